@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/umono-cms/cli/internal/confed"
+	"github.com/umono-cms/cli/internal/settings"
+)
+
+var (
+	configShowSecrets bool
+	configRestart     bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and edit project settings",
+	Long: `Read and edit the settings in the .env file of the Umono project in the current directory.
+
+Known settings are validated before they are written:
+  - PORT must be a number between 1024 and 65535
+  - APP_ENV must be dev or prod
+  - DSN must not be empty
+
+Secret values such as HASHED_PASSWORD are masked by list and get unless
+--show-secrets is given. Comments and formatting in .env are preserved. If Umono is running, you will be
+offered a restart so the change takes effect.
+
+Example:
+  umono config list
+  umono config get PORT
+  umono config set PORT 9000
+  umono config unset SESSION_DRIVER`,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings",
+	Args:  cobra.NoArgs,
+	Run:   runConfigList,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Args:  cobra.ExactArgs(1),
+	Run:   runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a setting",
+	Args:  cobra.ExactArgs(2),
+	Run:   runConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting",
+	Args:  cobra.ExactArgs(1),
+	Run:   runConfigUnset,
+}
+
+func init() {
+	configListCmd.Flags().BoolVar(&configShowSecrets, "show-secrets", false, "Show secret values instead of masking them")
+	configGetCmd.Flags().BoolVar(&configShowSecrets, "show-secrets", false, "Show a secret value instead of masking it")
+	configSetCmd.Flags().BoolVar(&configRestart, "restart", false, "Restart a running instance without asking")
+	configUnsetCmd.Flags().BoolVar(&configRestart, "restart", false, "Restart a running instance without asking")
+
+	configCmd.AddCommand(configListCmd, configGetCmd, configSetCmd, configUnsetCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// loadProjectEnv reads the .env of the project the command acts on.
+func loadProjectEnv() (string, string, *confed.EnvEditor) {
+	cwd := requireProject()
+
+	envPath := filepath.Join(cwd, ".env")
+	env := confed.NewEnvEditor()
+	if err := env.Read(envPath); os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Error: no .env found in %s\n", cwd)
+		os.Exit(1)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to read .env: %v\n", err)
+		os.Exit(1)
+	}
+
+	return cwd, envPath, env
+}
+
+func runConfigList(cmd *cobra.Command, args []string) {
+	_, _, env := loadProjectEnv()
+
+	for _, key := range env.Keys() {
+		value, _ := env.GetValue(key)
+		if settings.IsSecret(key) && !configShowSecrets {
+			fmt.Printf("%s=%s\n", key, settings.Mask(value))
+			continue
+		}
+		fmt.Printf("%s=%s\n", key, confed.QuoteValue(value))
+	}
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) {
+	_, _, env := loadProjectEnv()
+
+	value, ok := env.GetValue(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: %s is not set\n", args[0])
+		os.Exit(1)
+	}
+
+	if settings.IsSecret(args[0]) && !configShowSecrets {
+		value = settings.Mask(value)
+	}
+	fmt.Println(value)
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) {
+	key, value := args[0], args[1]
+
+	if !confed.ValidKey(key) {
+		fmt.Fprintf(os.Stderr, "Error: invalid key %q\n", key)
+		os.Exit(1)
+	}
+
+	if err := settings.Validate(key, value); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cwd, envPath, env := loadProjectEnv()
+	checkInstalledCompatibility(cwd, true)
+
+	if current, ok := env.GetValue(key); ok && current == value {
+		fmt.Printf("%s is already set to this value\n", key)
+		return
+	}
+
+	if err := env.SetValue(key, value).Write(envPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write .env: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ %s updated\n", key)
+	offerRestart(cmd, cwd, configRestart)
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) {
+	key := args[0]
+
+	if settings.IsRequired(key) {
+		fmt.Fprintf(os.Stderr, "Error: %s is required and cannot be removed\n", key)
+		os.Exit(1)
+	}
+
+	cwd, envPath, env := loadProjectEnv()
+	checkInstalledCompatibility(cwd, true)
+
+	if _, ok := env.GetValue(key); !ok {
+		fmt.Printf("%s is not set\n", key)
+		return
+	}
+
+	if err := env.Unset(key).Write(envPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write .env: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ %s removed\n", key)
+	offerRestart(cmd, cwd, configRestart)
+}
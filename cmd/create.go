@@ -4,12 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
-	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/umono-cms/cli/internal/project"
-	"golang.org/x/term"
+	"github.com/umono-cms/cli/internal/settings"
 )
 
 var createCmd = &cobra.Command{
@@ -22,6 +20,16 @@ This command will:
   - Extract it to a new directory
   - Set up initial configuration with your credentials
 
+Use --strict to refuse releases without signed checksums or a manifest.
+Use --version to pin a release, e.g. --version "~1.4".
+
+The password is entered twice and must be at least 10 characters, not a
+common password and not the same as the username. Use --weak-password to only
+require a non-empty one.
+
+The project is created in the current directory, or in the one given with
+-C.
+
 Example:
   umono create my-project
   cd my-project
@@ -31,15 +39,24 @@ Example:
 }
 
 func init() {
+	createCmd.Flags().BoolVar(&strict, "strict", false, "Refuse releases that cannot be fully verified")
+	createCmd.Flags().BoolVar(&weakPassword, "weak-password", false, "Skip the password strength policy")
+	createCmd.Flags().StringVar(&releaseVersion, "version", "", "Install the newest release matching a version constraint (e.g. \"^1.2\", \">=1.2 <2\")")
 	rootCmd.AddCommand(createCmd)
 }
 
 func runCreate(cmd *cobra.Command, args []string) {
 	projectName := args[0]
 
-	wd, err := os.Getwd()
+	strictVerification, err := strictMode(cmd)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to get working directory: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	wd, err := workingDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 	projectPath := filepath.Join(wd, projectName)
@@ -53,44 +70,40 @@ func runCreate(cmd *cobra.Command, args []string) {
 	fmt.Printf("📦 Creating new Umono project: '%s'\n", projectName)
 	fmt.Printf("   Configure root account credentials (you can change these later)\n\n")
 
-	fmt.Print("Username: ")
-	var username string
-	fmt.Scanln(&username)
+	username, err := promptUsername("Username", false)
+	if err != nil {
+		os.Remove(projectPath)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-	fmt.Print("Password: ")
-	passwordBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+	policy := passwordPolicy()
+	password, err := promptNewPassword("Password", func(password string) error {
+		return policy.Check(username, password)
+	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to read password: %v\n", err)
+		os.Remove(projectPath)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	password := strings.TrimSpace(string(passwordBytes))
-	fmt.Println()
 
 	var port string
 	for {
-		fmt.Print("Port [8999]: ")
-		var portInput string
-		fmt.Scanln(&portInput)
-		portInput = strings.TrimSpace(portInput)
-
-		if portInput == "" {
-			port = "8999"
-			break
-		}
-
-		portNum, err := strconv.Atoi(portInput)
+		fmt.Printf("Port [%s]: ", settings.DefaultPort)
+		portInput, err := readLine()
 		if err != nil {
-			fmt.Println("   ⚠️  Please enter a valid number")
-			continue
+			os.Remove(projectPath)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 
-		if portNum < 1 || portNum > 65535 {
-			fmt.Println("   ⚠️  Port must be between 1 and 65535")
-			continue
+		if portInput == "" {
+			port = settings.DefaultPort
+			break
 		}
 
-		if portNum < 1024 {
-			fmt.Println("   ⚠️  Ports below 1024 require root privileges")
+		if err := settings.ValidatePort(portInput); err != nil {
+			fmt.Printf("   ⚠️  Invalid port: %v\n", err)
 			continue
 		}
 
@@ -104,6 +117,8 @@ func runCreate(cmd *cobra.Command, args []string) {
 		Password: password,
 		Path:     projectPath,
 		Port:     port,
+		Strict:   strictVerification,
+		Version:  releaseVersion,
 	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -112,7 +127,11 @@ func runCreate(cmd *cobra.Command, args []string) {
 
 	fmt.Printf("✅ Project '%s' created successfully!\n\n", projectName)
 	fmt.Println("Next steps:")
-	fmt.Printf("  cd %s\n", projectName)
+	if projectFlag != "" {
+		fmt.Printf("  cd %s\n", projectPath)
+	} else {
+		fmt.Printf("  cd %s\n", projectName)
+	}
 	fmt.Println("  umono up")
 	fmt.Println()
 }
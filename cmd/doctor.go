@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/umono-cms/cli/internal/doctor"
+	"github.com/umono-cms/cli/internal/project"
+)
+
+var (
+	doctorFix  bool
+	doctorJSON bool
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose common environment and project problems",
+	Long: `Check the environment and the Umono project in the current directory for common problems.
+
+This command will check:
+  - ~/.local/bin is on PATH
+  - The umono binary exists, is executable and is built for this machine
+  - .env contains every key from .env.example
+  - The port in .env is free
+  - There is no stale state file
+  - There is enough free disk space
+
+Use --fix to apply safe automatic repairs, and --json for machine-readable output.
+
+Example:
+  cd my-project
+  umono doctor --fix`,
+	Run: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Apply safe automatic repairs")
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Print results as JSON")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	// Outside a project the checks still run, reporting what is missing.
+	cwd, err := findProject()
+	if err != nil && !errors.Is(err, project.ErrNotFound) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	results := doctor.Run(cwd, doctorFix)
+
+	if doctorJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		printDoctorResults(results)
+	}
+
+	if doctor.HasFailures(results) {
+		os.Exit(1)
+	}
+}
+
+func printDoctorResults(results []doctor.Result) {
+	fmt.Println("🩺 Checking Umono environment...")
+	fmt.Println()
+
+	for _, result := range results {
+		icon := "✅"
+		switch result.Status {
+		case doctor.Warn:
+			icon = "⚠️ "
+		case doctor.Fail:
+			icon = "❌"
+		}
+
+		fixed := ""
+		if result.Fixed {
+			fixed = " (fixed)"
+		}
+
+		fmt.Printf("%s %-13s %s%s\n", icon, result.Name, result.Message, fixed)
+		if result.Status != doctor.Pass && result.Hint != "" {
+			fmt.Printf("   %-13s → %s\n", "", result.Hint)
+		}
+	}
+
+	fmt.Println()
+}
@@ -1,12 +1,10 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
-	"strconv"
-	"strings"
-	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -14,60 +12,46 @@ import (
 var downCmd = &cobra.Command{
 	Use:   "down",
 	Short: "Stop Umono",
-	Long:  `Stop the running Umono application in the current directory.`,
+	Long:  `Stop the running Umono application in the current directory. Umono is asked to exit with SIGTERM; if it is still running after --timeout, it and its child processes are killed with SIGKILL.`,
 	Run:   runDown,
 }
 
+var downTimeout time.Duration
+
 func init() {
+	downCmd.Flags().DurationVar(&downTimeout, "timeout", 10*time.Second, "How long to wait for Umono to exit before killing it")
 	rootCmd.AddCommand(downCmd)
 }
 
 func runDown(cmd *cobra.Command, args []string) {
-	cwd, err := os.Getwd()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
-		os.Exit(1)
-	}
+	cwd := requireProject()
 
-	pidPath := filepath.Join(cwd, ".PID")
-
-	pidData, err := os.ReadFile(pidPath)
-	if os.IsNotExist(err) {
-		fmt.Println("Umono is not running (no .PID file found)")
-		os.Exit(0)
-	}
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to read .PID file: %v\n", err)
-		os.Exit(1)
+	if unit := managedService(cwd); unit != nil {
+		runServiceAction(unit.Stop, "Umono service stopped")
+		return
 	}
 
-	pidStr := strings.TrimSpace(string(pidData))
-	pid, err := strconv.Atoi(pidStr)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: invalid PID in .PID file: %s\n", pidStr)
-		os.Remove(pidPath)
-		os.Exit(1)
+	inst, err := runningInstance(cwd)
+	if errors.Is(err, errNotRunning) {
+		fmt.Println("Umono is not running")
+		return
 	}
-
-	process, err := os.FindProcess(pid)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to find process: %v\n", err)
-		os.Remove(pidPath)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Run 'umono doctor --fix' to remove an unreadable state file.")
 		os.Exit(1)
 	}
 
-	if err := process.Signal(syscall.Signal(0)); err != nil {
-		fmt.Println("Umono is not running (stale .PID file removed)")
-		os.Remove(pidPath)
-		os.Exit(0)
-	}
+	fmt.Printf("⏳ Stopping Umono (PID: %d)...\n", inst.PID)
 
-	if err := process.Signal(syscall.SIGTERM); err != nil {
+	_, killed, err := stopInstance(cwd, downTimeout)
+	if err != nil && !errors.Is(err, errNotRunning) {
 		fmt.Fprintf(os.Stderr, "Error: failed to stop umono: %v\n", err)
 		os.Exit(1)
 	}
 
-	os.Remove(pidPath)
-
-	fmt.Println("Umono stopped (PID:", pid, ")")
+	if killed {
+		fmt.Printf("⚠️  Umono did not exit within %s and was killed\n", downTimeout)
+	}
+	fmt.Println("Umono stopped (PID:", inst.PID, ")")
 }
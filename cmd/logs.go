@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/umono-cms/cli/internal/logfile"
+	"golang.org/x/term"
+)
+
+var (
+	logsFollow bool
+	logsTail   int
+	logsSince  string
+	logsGrep   string
+	logsJSON   bool
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show the output of a detached Umono",
+	Long: `Show the output that Umono wrote while running in background (detached mode).
+
+This command will:
+  - Read the current log file and the rotated ones, oldest first
+  - Filter lines by time (--since) and regular expression (--grep)
+  - Keep printing new lines with -f, also after the log is rotated
+
+JSON log lines are shown as "time LEVEL message key=value" with the level
+colored. Use --json to print the raw lines instead, e.g. for piping to jq.
+
+Example:
+  umono logs --tail 100
+  umono logs -f --grep error
+  umono logs --since 10m --json | jq .msg`,
+	Args: cobra.NoArgs,
+	Run:  runLogs,
+}
+
+func init() {
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Keep printing new lines")
+	logsCmd.Flags().IntVarP(&logsTail, "tail", "n", -1, "Only show the last N lines")
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "Only show lines since a duration ago (10m) or a time (2006-01-02)")
+	logsCmd.Flags().StringVar(&logsGrep, "grep", "", "Only show lines matching a regular expression")
+	logsCmd.Flags().BoolVar(&logsJSON, "json", false, "Print raw lines without formatting")
+	logsCmd.Flags().StringVar(&logFile, "log-file", "", "Read this log file instead of the configured one")
+	rootCmd.AddCommand(logsCmd)
+}
+
+func runLogs(cmd *cobra.Command, args []string) {
+	cwd := requireProject()
+
+	opts, err := supervisorOptions(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	filter := &logfile.Filter{}
+	if logsSince != "" {
+		filter.Since, err = logfile.ParseSince(logsSince, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if logsGrep != "" {
+		filter.Pattern, err = regexp.Compile(logsGrep)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --grep pattern: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	files := logfile.Files(opts.Log.Path)
+	if len(files) == 0 && !logsFollow {
+		fmt.Fprintf(os.Stderr, "Error: no logs found at %s (logs are written by 'umono up -d')\n", displayPath(cwd, opts.Log.Path))
+		os.Exit(1)
+	}
+
+	color := !logsJSON && os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(os.Stdout.Fd()))
+	show := func(text string) {
+		if logsJSON {
+			fmt.Println(text)
+		} else {
+			fmt.Println(logfile.Format(logfile.ParseLine(text), color))
+		}
+	}
+
+	// The current file is read through the follower so that -f continues
+	// exactly where the initial output stopped. It may be missing while
+	// rotated files exist; the follower then waits for it to appear.
+	if len(files) > 0 && files[len(files)-1] == opts.Log.Path {
+		files = files[:len(files)-1]
+	}
+
+	var lines []string
+	for _, path := range files {
+		f, err := logfile.NewFollower(path)
+		if err != nil {
+			continue
+		}
+		more, _ := f.Poll()
+		lines = append(lines, more...)
+		lines = append(lines, f.Flush()...)
+		f.Close()
+	}
+
+	current, err := logfile.NewFollower(opts.Log.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to open log: %v\n", err)
+		os.Exit(1)
+	}
+	defer current.Close()
+
+	more, err := current.Poll()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to read log: %v\n", err)
+		os.Exit(1)
+	}
+	lines = append(lines, more...)
+	if !logsFollow {
+		lines = append(lines, current.Flush()...)
+	}
+
+	var matched []string
+	for _, text := range lines {
+		if filter.Match(logfile.ParseLine(text)) {
+			matched = append(matched, text)
+		}
+	}
+	if logsTail >= 0 && len(matched) > logsTail {
+		matched = matched[len(matched)-logsTail:]
+	}
+
+	for _, text := range matched {
+		show(text)
+	}
+
+	if !logsFollow {
+		return
+	}
+
+	for {
+		time.Sleep(250 * time.Millisecond)
+
+		lines, err := current.Poll()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read log: %v\n", err)
+			os.Exit(1)
+		}
+		for _, text := range lines {
+			if filter.Match(logfile.ParseLine(text)) {
+				show(text)
+			}
+		}
+	}
+}
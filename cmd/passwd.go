@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/umono-cms/cli/internal/credentials"
+	"github.com/umono-cms/cli/internal/project"
+	"golang.org/x/term"
+)
+
+var (
+	passwdUsername      string
+	passwdPasswordStdin bool
+	passwdRestart       bool
+)
+
+var passwdCmd = &cobra.Command{
+	Use:   "passwd",
+	Short: "Change the root account credentials",
+	Long: `Change the root account username and password of the Umono project in the current directory.
+
+This command will:
+  - Prompt for a new username and password (with confirmation)
+  - Check the password against the strength policy
+  - Store bcrypt hashes of them in .env
+  - Clear any plaintext USERNAME and PASSWORD values
+  - Offer to restart Umono if it is running
+
+Leave the username empty to keep the current one. For scripts, pass the
+username with --username and the password on stdin with --password-stdin.
+
+Passwords must be at least 10 characters, not a common password and not the
+same as the username. Use --weak-password to only require a non-empty one.
+
+Example:
+  umono passwd
+  echo "$NEW_PASSWORD" | umono passwd --username admin --password-stdin --restart`,
+	Args: cobra.NoArgs,
+	Run:  runPasswd,
+}
+
+func init() {
+	passwdCmd.Flags().StringVar(&passwdUsername, "username", "", "New username (keeps the current one if empty)")
+	passwdCmd.Flags().BoolVar(&passwdPasswordStdin, "password-stdin", false, "Read the new password from stdin")
+	passwdCmd.Flags().BoolVar(&weakPassword, "weak-password", false, "Skip the password strength policy")
+	passwdCmd.Flags().BoolVar(&passwdRestart, "restart", false, "Restart a running instance without asking")
+	rootCmd.AddCommand(passwdCmd)
+}
+
+func runPasswd(cmd *cobra.Command, args []string) {
+	cwd := requireProject()
+
+	checkInstalledCompatibility(cwd, true)
+
+	username := strings.TrimSpace(passwdUsername)
+
+	policy := passwordPolicy()
+	check := func(password string) error {
+		if err := policy.Check(username, password); err != nil {
+			return err
+		}
+		// The current username is only known as a hash.
+		if policy.RejectUsername && username == "" && project.MatchesUsername(cwd, password) {
+			return errors.New("password must not be the same as the username")
+		}
+		return nil
+	}
+
+	if cmd.Flags().Changed("username") {
+		if err := credentials.ValidateUsername(username); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var (
+		password string
+		err      error
+	)
+	if passwdPasswordStdin {
+		password, err = readPasswordStdin(os.Stdin)
+		if err == nil {
+			err = check(password)
+		}
+	} else {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			fmt.Fprintf(os.Stderr, "Error: stdin is not a terminal; use --password-stdin\n")
+			os.Exit(1)
+		}
+
+		if !cmd.Flags().Changed("username") {
+			username, err = promptUsername("New username (leave empty to keep the current one)", true)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		password, err = promptNewPassword("New password", check)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := project.SetCredentials(cwd, username, password); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("✅ Credentials updated")
+	offerRestart(cmd, cwd, passwdRestart)
+}
+
+// promptNewPassword asks for a password twice without echoing it, until it
+// is confirmed and passes check.
+func promptNewPassword(label string, check func(string) error) (string, error) {
+	for {
+		fmt.Printf("%s: ", label)
+		passwordBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		if err != nil {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+
+		password := strings.TrimSpace(string(passwordBytes))
+		if err := check(password); err != nil {
+			fmt.Printf("   ⚠️  %s\n", err)
+			continue
+		}
+
+		fmt.Printf("Confirm %s: ", strings.ToLower(label))
+		confirmBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		if err != nil {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+
+		if password != strings.TrimSpace(string(confirmBytes)) {
+			fmt.Println("   ⚠️  Passwords do not match")
+			continue
+		}
+
+		return password, nil
+	}
+}
+
+// promptUsername asks until a valid username is entered. With allowEmpty an
+// empty answer is returned as is.
+func promptUsername(label string, allowEmpty bool) (string, error) {
+	for {
+		fmt.Printf("%s: ", label)
+		username, err := readLine()
+		if err != nil {
+			return "", err
+		}
+
+		if username == "" && allowEmpty {
+			return "", nil
+		}
+
+		if err := credentials.ValidateUsername(username); err != nil {
+			fmt.Printf("   ⚠️  %s\n", err)
+			continue
+		}
+
+		return username, nil
+	}
+}
+
+// stdin is shared by every prompt so that input buffered while reading one
+// answer is not lost to the next.
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine reads one answer from stdin. At the end of input it fails rather
+// than returning empty answers forever.
+func readLine() (string, error) {
+	line, err := stdin.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", errors.New("unexpected end of input")
+	}
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func readPasswordStdin(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+
+	password := strings.TrimRight(line, "\r\n")
+	return password, nil
+}
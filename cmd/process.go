@@ -0,0 +1,509 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/umono-cms/cli/internal/config"
+	"github.com/umono-cms/cli/internal/logfile"
+	"github.com/umono-cms/cli/internal/service"
+	"github.com/umono-cms/cli/internal/state"
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+var errNotRunning = errors.New("umono is not running")
+
+// instance is a running Umono started by this CLI.
+type instance struct {
+	PID int
+	// Detached instances run under a supervisor that leads its own process
+	// group. Foreground ones lead their own group too, which is given the
+	// terminal while they run.
+	Detached bool
+	// exited is closed when a process started by this CLI has been reaped.
+	exited chan struct{}
+}
+
+func (i *instance) alive() bool {
+	if i.exited != nil {
+		select {
+		case <-i.exited:
+			return false
+		default:
+			return true
+		}
+	}
+	return syscall.Kill(i.PID, 0) == nil
+}
+
+// runningInstance returns the project's live instance, or errNotRunning.
+func runningInstance(dir string) (*instance, error) {
+	st, err := state.Running(dir)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		return nil, errNotRunning
+	}
+
+	detached := st.Mode == state.Detached
+	if st.Legacy {
+		// Older CLIs started detached instances in their own process group.
+		pgid, err := syscall.Getpgid(st.PID)
+		detached = err == nil && pgid == st.PID
+	}
+
+	return &instance{PID: st.PID, Detached: detached}, nil
+}
+
+// lockIdle locks the project's state for starting a new instance and fails
+// if one is already running. The caller records the new instance and closes
+// the handle.
+func lockIdle(dir string) (*state.Handle, error) {
+	h, err := state.Open(dir, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if st, err := h.Read(); err == nil && st.Alive() {
+		h.Close()
+		return nil, fmt.Errorf("umono is already running (PID: %d)", st.PID)
+	}
+
+	return h, nil
+}
+
+func recordInstance(h *state.Handle, pid int, mode state.Mode) {
+	if err := h.Write(state.New(pid, mode)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record the running instance: %v\n", err)
+	}
+}
+
+// executablePath returns the project's umono binary if it can be run.
+func executablePath(dir string) (string, error) {
+	umonoPath := filepath.Join(dir, "umono")
+
+	info, err := os.Stat(umonoPath)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("umono executable not found in %s", dir)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if info.Mode()&0o111 == 0 {
+		return "", errors.New("umono file exists but is not executable")
+	}
+
+	return umonoPath, nil
+}
+
+// startDetached starts a supervisor that runs Umono in the background and
+// writes its output to the log. The restart policy comes from --restart and
+// --max-restarts.
+func startDetached(dir string) (*instance, error) {
+	opts, err := supervisorOptions(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the umono CLI: %w", err)
+	}
+
+	h, err := lockIdle(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+
+	execCmd := exec.Command(self, "supervise",
+		"--log-file", opts.Log.Path,
+		"--restart", string(opts.Restart),
+		"--max-restarts", strconv.Itoa(opts.MaxRestarts))
+	execCmd.Dir = dir
+	execCmd.Stdout = nil
+	execCmd.Stderr = nil
+	execCmd.Stdin = nil
+
+	execCmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+
+	if err := execCmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start umono: %w", err)
+	}
+
+	inst := &instance{PID: execCmd.Process.Pid, Detached: true, exited: make(chan struct{})}
+	recordInstance(h, inst.PID, state.Detached)
+
+	// Reap the supervisor if it exits while this CLI is still waiting for
+	// it to become ready.
+	go func() {
+		execCmd.Wait()
+		close(inst.exited)
+	}()
+
+	return inst, nil
+}
+
+// runForeground runs Umono attached to the terminal until it exits. started
+// is called once the process is running. SIGINT, SIGTERM and SIGHUP sent to
+// the CLI are forwarded to Umono, and the state is removed however it exits.
+func runForeground(dir string, started func(*instance)) error {
+	umonoPath, err := executablePath(dir)
+	if err != nil {
+		return err
+	}
+
+	h, err := lockIdle(dir)
+	if err != nil {
+		return err
+	}
+
+	execCmd := exec.Command(umonoPath)
+	execCmd.Dir = dir
+	execCmd.Stdin = os.Stdin
+	execCmd.Stdout = os.Stdout
+	execCmd.Stderr = os.Stderr
+
+	// In its own process group Umono does not also receive signals sent to
+	// the CLI's group, so each forwarded signal reaches it exactly once. When
+	// the CLI owns the terminal, the terminal is handed to Umono's group, so
+	// Ctrl-C goes to Umono directly and it can still read and write the
+	// terminal; the CLI takes it back once Umono exits.
+	execCmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+	tty := int(os.Stdin.Fd())
+	ownsTerminal := ownsTerminal(tty)
+	if ownsTerminal {
+		execCmd.SysProcAttr.Foreground = true
+		execCmd.SysProcAttr.Ctty = tty
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	if err := execCmd.Start(); err != nil {
+		h.Close()
+		return fmt.Errorf("failed to start umono: %w", err)
+	}
+
+	if ownsTerminal {
+		defer reclaimTerminal(tty)
+	}
+
+	pid := execCmd.Process.Pid
+	recordInstance(h, pid, state.Foreground)
+	h.Close()
+	defer state.RemoveIf(dir, pid)
+
+	if started != nil {
+		started(&instance{PID: pid})
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- execCmd.Wait()
+	}()
+
+	for {
+		select {
+		case sig := <-signals:
+			execCmd.Process.Signal(sig)
+		case err := <-exited:
+			return err
+		}
+	}
+}
+
+// ownsTerminal reports whether fd is a terminal whose foreground process
+// group is the CLI's.
+func ownsTerminal(fd int) bool {
+	if !term.IsTerminal(fd) {
+		return false
+	}
+	pgrp, err := unix.IoctlGetInt(fd, unix.TIOCGPGRP)
+	return err == nil && pgrp == syscall.Getpgrp()
+}
+
+// reclaimTerminal makes the CLI's process group the terminal's foreground
+// group again. SIGTTOU is ignored meanwhile, as the CLI is a background group
+// until the call succeeds.
+func reclaimTerminal(fd int) {
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+
+	unix.IoctlSetPointerInt(fd, unix.TIOCSPGRP, syscall.Getpgrp())
+}
+
+// exitCode maps the result of running Umono to the CLI's exit code, using
+// the shell convention 128+n for a process killed by signal n.
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		if err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return exitErr.ExitCode()
+}
+
+// stopInstance stops the running instance and removes its state once it is
+// gone.
+// It returns errNotRunning when nothing was running.
+func stopInstance(dir string, timeout time.Duration) (*instance, bool, error) {
+	inst, err := runningInstance(dir)
+	if err != nil {
+		return nil, false, err
+	}
+
+	killed, err := stopProcess(inst.PID, timeout)
+	if err != nil {
+		return inst, killed, err
+	}
+
+	state.RemoveIf(dir, inst.PID)
+	return inst, killed, nil
+}
+
+// processRunning reports whether pid exists and has not exited. An exited
+// process that was not reaped yet still exists as a zombie.
+func processRunning(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return true
+	}
+	if end := strings.LastIndexByte(string(stat), ')'); end >= 0 {
+		fields := strings.Fields(string(stat[end+1:]))
+		return len(fields) == 0 || fields[0] != "Z"
+	}
+	return true
+}
+
+// stopProcess asks pid to exit with SIGTERM and waits up to timeout before
+// sending SIGKILL to its process group. It only returns without error once
+// the process is gone, and reports whether it had to be killed.
+func stopProcess(pid int, timeout time.Duration) (bool, error) {
+	// Detached instances lead their own process group, which also holds
+	// the server started by the supervisor.
+	group := false
+	if pgid, err := syscall.Getpgid(pid); err == nil && pgid == pid {
+		group = true
+	}
+
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		if err == syscall.ESRCH {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// Only the leader is waited for. Processes the server left behind may
+	// keep running after it exits; they get SIGTERM once the leader is gone.
+	if waitFor(func() bool { return !processRunning(pid) }, timeout) {
+		if group {
+			syscall.Kill(-pid, syscall.SIGTERM)
+		}
+		return false, nil
+	}
+
+	target := pid
+	if group {
+		target = -pid
+	}
+	if err := syscall.Kill(target, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+		return true, err
+	}
+
+	if !waitFor(func() bool { return !processRunning(pid) }, 5*time.Second) {
+		return true, fmt.Errorf("process %d is still running after SIGKILL", pid)
+	}
+
+	return true, nil
+}
+
+// waitPortReleased waits until port can be bound again.
+func waitPortReleased(port string, timeout time.Duration) error {
+	free := func() bool {
+		listener, err := net.Listen("tcp", ":"+port)
+		if err != nil {
+			return false
+		}
+		listener.Close()
+		return true
+	}
+
+	if !waitFor(free, timeout) {
+		return fmt.Errorf("port %s is still in use after %s", port, timeout)
+	}
+	return nil
+}
+
+// waitReady polls http://localhost:<port><path> until Umono answers. It
+// fails early when the process exits.
+func waitReady(inst *instance, port, path string, timeout time.Duration) error {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	url := "http://127.0.0.1:" + port + path
+	client := &http.Client{Timeout: 2 * time.Second}
+
+	exited := false
+	ready := func() bool {
+		if !inst.alive() {
+			exited = true
+			return true
+		}
+		resp, err := client.Get(url)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode < http.StatusInternalServerError
+	}
+
+	if !waitFor(ready, timeout) {
+		return fmt.Errorf("umono did not respond at %s within %s", url, timeout)
+	}
+	if exited {
+		return errors.New("umono exited during startup")
+	}
+	return nil
+}
+
+// abandonStart stops an instance that did not become ready, so that a failed
+// start leaves nothing running.
+func abandonStart(dir string, inst *instance) {
+	if !inst.alive() {
+		state.RemoveIf(dir, inst.PID)
+		return
+	}
+	if _, _, err := stopInstance(dir, downTimeout); err != nil && !errors.Is(err, errNotRunning) {
+		fmt.Fprintf(os.Stderr, "Warning: failed to stop Umono (PID: %d), which is still running: %v\n", inst.PID, err)
+		return
+	}
+	fmt.Printf("⏹️  Stopped Umono (PID: %d), which did not become ready\n", inst.PID)
+}
+
+// startupError adds the last lines Umono logged to a failed start.
+func startupError(dir string, err error) error {
+	opts, optsErr := supervisorOptions(dir)
+	if optsErr != nil {
+		return err
+	}
+
+	lines := logfile.Tail(opts.Log.Path, 20)
+	if len(lines) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w\n\nLast output (%s):\n  %s", err, displayPath(dir, opts.Log.Path), strings.Join(lines, "\n  "))
+}
+
+// healthPath resolves --health-path, falling back to the global config.
+func healthPath(cmd *cobra.Command) string {
+	if cmd.Flags().Changed("health-path") {
+		return healthCheckPath
+	}
+	if cfg, err := config.Load(); err == nil && cfg.HealthPath != "" {
+		return cfg.HealthPath
+	}
+	return healthCheckPath
+}
+
+func waitFor(done func() bool, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for !done() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return true
+}
+
+// offerRestart restarts a running instance so it picks up a changed .env.
+// Without --restart the user is asked, or told to restart by hand when
+// stdin is not a terminal. A service is restarted through systemctl.
+func offerRestart(cmd *cobra.Command, dir string, restart bool) {
+	if unit := service.Find(dir); unit != nil {
+		status, err := unit.Status()
+		if err != nil || status.ActiveState != "active" {
+			return
+		}
+		if restart || confirmRestart() {
+			runServiceAction(unit.Restart, "Umono service restarted")
+		}
+		return
+	}
+
+	inst, err := runningInstance(dir)
+	if err != nil {
+		return
+	}
+
+	if !inst.Detached {
+		fmt.Println("ℹ️  Umono is running in the foreground. Restart it there to apply the change.")
+		return
+	}
+
+	if !restart && !confirmRestart() {
+		return
+	}
+
+	inheritRestartPolicy(dir, inst)
+	if err := restartInstance(dir, healthPath(cmd)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// confirmRestart asks whether to restart Umono now. When it cannot ask or
+// the answer is no, it tells the user how to restart later.
+func confirmRestart() bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Println("ℹ️  Umono is running. Run 'umono restart' to apply the change.")
+		return false
+	}
+
+	fmt.Print("Umono is running. Restart it now to apply the change? [Y/n]: ")
+	answer, err := readLine()
+	answer = strings.ToLower(answer)
+	if err != nil || answer != "" && answer != "y" && answer != "yes" {
+		fmt.Println("ℹ️  Run 'umono restart' to apply the change.")
+		return false
+	}
+	return true
+}
+
+// displayPath shows path relative to the project when it is inside it.
+func displayPath(projectPath, path string) string {
+	if rel, err := filepath.Rel(projectPath, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
@@ -1,22 +1,173 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/spf13/cobra"
+	"github.com/umono-cms/cli/internal/supervisor"
 )
 
+var restartForeground bool
+
 var restartCmd = &cobra.Command{
 	Use:   "restart",
 	Short: "Restart Umono",
-	Long:  `Restart the Umono application in the current directory. Equivalent to running 'down' followed by 'up'.`,
-	Run:   runRestart,
+	Long: `Restart the Umono application in the current directory, or start it if it is not running.
+
+This command will:
+  - Stop the running instance, waiting up to --timeout before killing it
+  - Wait for its port to be released
+  - Start a new instance in the same mode (background or foreground) and
+    with the same restart policy
+  - Wait until the new instance responds over HTTP, up to --wait-timeout
+    (disable with --wait=false)
+
+Use -d or --foreground to change the mode. A stopped project starts in the
+foreground unless -d is given.
+
+Example:
+  umono restart
+  umono restart -d`,
+	Run: runRestart,
 }
 
 func init() {
 	restartCmd.Flags().BoolVarP(&detach, "detach", "d", false, "Run in background")
+	restartCmd.Flags().BoolVar(&restartForeground, "foreground", false, "Run in the foreground")
+	restartCmd.Flags().DurationVar(&downTimeout, "timeout", 10*time.Second, "How long to wait for Umono to stop before killing it")
+	restartCmd.Flags().BoolVar(&waitForReady, "wait", true, "Wait until Umono responds over HTTP")
+	restartCmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 30*time.Second, "How long to wait for Umono to respond")
+	restartCmd.Flags().StringVar(&healthCheckPath, "health-path", "/", "Path requested to check that Umono is ready")
+	restartCmd.Flags().StringVar(&restartPolicy, "restart", "no", "With -d, restart Umono when it exits: no, on-failure or always")
+	restartCmd.Flags().IntVar(&maxRestarts, "max-restarts", 0, "Give up after this many restarts in a row (0 for no limit)")
+	restartCmd.MarkFlagsMutuallyExclusive("detach", "foreground")
 	rootCmd.AddCommand(restartCmd)
 }
 
 func runRestart(cmd *cobra.Command, args []string) {
-	runDown(cmd, args)
-	runUp(cmd, args)
+	cwd := requireProject()
+
+	if _, err := executablePath(cwd); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	checkInstalledCompatibility(cwd, true)
+
+	if unit := managedService(cwd); unit != nil {
+		runServiceAction(unit.Restart, "Umono service restarted")
+		return
+	}
+
+	detached := false
+	if inst, err := runningInstance(cwd); err == nil {
+		detached = inst.Detached
+		if !cmd.Flags().Changed("restart") && !cmd.Flags().Changed("max-restarts") {
+			inheritRestartPolicy(cwd, inst)
+		}
+	}
+	if cmd.Flags().Changed("detach") {
+		detached = detach
+	}
+	if restartForeground {
+		detached = false
+	}
+	requireDetachedForRestart(cmd, detached)
+
+	if detached {
+		if err := restartInstance(cwd, healthPath(cmd)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	port, err := stopForRestart(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	health := healthPath(cmd)
+	err = runForeground(cwd, func(inst *instance) {
+		fmt.Printf("▶️  Started Umono (PID: %d)\n", inst.PID)
+		if waitForReady && port != "" {
+			go func() {
+				if waitReady(inst, port, health, waitTimeout) == nil {
+					fmt.Printf("✅ Umono is running at http://localhost:%s\n", port)
+				}
+			}()
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Umono exited with error: %v\n", err)
+	}
+	os.Exit(exitCode(err))
+}
+
+// restartInstance stops the running instance, if any, and starts a new one in
+// the background.
+func restartInstance(dir string, health string) error {
+	port, err := stopForRestart(dir)
+	if err != nil {
+		return err
+	}
+
+	inst, err := startDetached(dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("▶️  Started Umono in background (PID: %d)\n", inst.PID)
+
+	if waitForReady && port != "" {
+		if err := waitReady(inst, port, health, waitTimeout); err != nil {
+			err = startupError(dir, err)
+			abandonStart(dir, inst)
+			return err
+		}
+		fmt.Printf("✅ Umono is running at http://localhost:%s\n", port)
+	}
+	return nil
+}
+
+// stopForRestart stops the running instance, if any, and waits for its port
+// to be released. It returns the port read from .env.
+func stopForRestart(dir string) (string, error) {
+	port := readPortFromEnv(dir)
+
+	old, killed, err := stopInstance(dir, downTimeout)
+	switch {
+	case errors.Is(err, errNotRunning):
+		fmt.Println("⏹️  Umono is not running")
+	case err != nil:
+		return "", fmt.Errorf("failed to stop umono: %w", err)
+	default:
+		if killed {
+			fmt.Printf("⚠️  Umono did not exit within %s and was killed\n", downTimeout)
+		}
+		fmt.Printf("⏹️  Stopped Umono (PID: %d)\n", old.PID)
+
+		if port != "" {
+			fmt.Printf("⏳ Waiting for port %s to be released...\n", port)
+			if err := waitPortReleased(port, downTimeout); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return port, nil
+}
+
+// inheritRestartPolicy reuses the restart policy of a running supervisor for
+// the instance that replaces it.
+func inheritRestartPolicy(dir string, inst *instance) {
+	status, err := supervisor.LoadStatus(supervisorStatusPath(dir))
+	if err != nil || status.PID != inst.PID {
+		return
+	}
+	restartPolicy = string(status.Policy)
+	maxRestarts = status.MaxRestarts
 }
@@ -1,10 +1,23 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
+	"github.com/umono-cms/cli/internal/compatibility"
+	"github.com/umono-cms/cli/internal/config"
+	"github.com/umono-cms/cli/internal/credentials"
+	"github.com/umono-cms/cli/internal/project"
+)
+
+var (
+	strict         bool
+	releaseVersion string
+	weakPassword   bool
+	projectFlag    string
 )
 
 var rootCmd = &cobra.Command{
@@ -12,6 +25,10 @@ var rootCmd = &cobra.Command{
 	Short: "Umono CLI",
 }
 
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&projectFlag, "project", "C", "", "Run as if started in <path> instead of the current directory")
+}
+
 func Execute() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
@@ -20,3 +37,100 @@ func Execute() {
 		os.Exit(1)
 	}
 }
+
+// strictMode resolves --strict, falling back to the global config when the
+// flag was not given explicitly.
+func strictMode(cmd *cobra.Command) (bool, error) {
+	if cmd.Flags().Changed("strict") {
+		return strict, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return false, err
+	}
+
+	return cfg.Strict, nil
+}
+
+// findProject resolves the project a command acts on: the nearest project
+// root at or above --project, or the current directory when it is not set.
+// When there is none, it returns the starting directory and
+// project.ErrNotFound.
+func findProject() (string, error) {
+	start, err := workingDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := project.Find(start)
+	if errors.Is(err, project.ErrNotFound) {
+		return start, err
+	}
+	return dir, err
+}
+
+// workingDir is the absolute path of --project, or the current directory
+// when it is not set.
+func workingDir() (string, error) {
+	if projectFlag == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get current directory: %w", err)
+		}
+		return wd, nil
+	}
+
+	info, err := os.Stat(projectFlag)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", projectFlag)
+	}
+	return filepath.Abs(projectFlag)
+}
+
+// requireProject is findProject for commands that cannot run outside a
+// project.
+func requireProject() string {
+	dir, err := findProject()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return dir
+}
+
+func passwordPolicy() credentials.Policy {
+	if weakPassword {
+		return credentials.RelaxedPolicy
+	}
+	return credentials.DefaultPolicy
+}
+
+// checkInstalledCompatibility compares the CLI with the manifest of the
+// project's installed release. Incompatibilities stop the command when
+// refuse is set and are reported as a warning otherwise.
+func checkInstalledCompatibility(projectPath string, refuse bool) {
+	result, err := compatibility.CheckInstalled(projectPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to check compatibility with the installed release: %v\n", err)
+		return
+	}
+
+	if result == nil || result.Compatible {
+		return
+	}
+
+	if refuse {
+		fmt.Fprint(os.Stderr, compatibility.FormatIncompatibleError(result))
+		fmt.Fprintf(os.Stderr, "\nThis project runs Umono %s. Upgrade your CLI before managing it:\n  → %s\n", result.UmonoVersion, compatibility.CLIUpgradeURL)
+		os.Exit(1)
+	}
+
+	fmt.Printf("⚠️  Warning: this CLI is not compatible with the installed Umono %s\n", result.UmonoVersion)
+	for _, v := range result.Violations {
+		fmt.Printf("   - %s\n", v.Reason)
+	}
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/umono-cms/cli/internal/service"
+)
+
+var (
+	serviceUser   bool
+	serviceSystem bool
+)
+
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Run Umono as a systemd service",
+	Long: `Manage a systemd service that runs the Umono project in the current directory, so it starts at boot and is restarted when it crashes.
+
+By default a user service is installed (--user). Use --system to install a
+system-wide service, which requires root.
+
+While a service is installed, 'umono up', 'down', 'restart' and 'status' control
+it through systemctl. Its output goes to the journal:
+  journalctl --user -u <unit>
+
+Example:
+  umono service install
+  sudo umono service install --system
+  umono service status
+  umono service uninstall`,
+}
+
+var serviceInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install, enable and start the service",
+	Args:  cobra.NoArgs,
+	Run:   runServiceInstall,
+}
+
+var serviceUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Stop, disable and remove the service",
+	Args:  cobra.NoArgs,
+	Run:   runServiceUninstall,
+}
+
+var serviceStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state of the service",
+	Args:  cobra.NoArgs,
+	Run:   runServiceStatus,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{serviceInstallCmd, serviceUninstallCmd, serviceStatusCmd} {
+		c.Flags().BoolVar(&serviceUser, "user", false, "Use a user service (default)")
+		c.Flags().BoolVar(&serviceSystem, "system", false, "Use a system service")
+		c.MarkFlagsMutuallyExclusive("user", "system")
+		serviceCmd.AddCommand(c)
+	}
+	rootCmd.AddCommand(serviceCmd)
+}
+
+func serviceScope() service.Scope {
+	if serviceSystem {
+		return service.System
+	}
+	return service.User
+}
+
+// serviceUnit returns the unit selected by --user/--system, or the installed
+// one when neither was given.
+func serviceUnit(cmd *cobra.Command, dir string) *service.Unit {
+	if !cmd.Flags().Changed("user") && !cmd.Flags().Changed("system") {
+		if u := service.Find(dir); u != nil {
+			return u
+		}
+	}
+	return service.NewUnit(dir, serviceScope())
+}
+
+func runServiceInstall(cmd *cobra.Command, args []string) {
+	cwd := requireProject()
+
+	if _, err := executablePath(cwd); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	checkInstalledCompatibility(cwd, true)
+
+	unit := service.NewUnit(cwd, serviceScope())
+	if unit.Scope == service.System {
+		if os.Geteuid() != 0 {
+			fmt.Fprintf(os.Stderr, "Error: installing a system service requires root; run it with sudo\n")
+			os.Exit(1)
+		}
+		unit.RunAs = os.Getenv("SUDO_USER")
+	}
+
+	if inst, err := runningInstance(cwd); err == nil {
+		fmt.Fprintf(os.Stderr, "Error: Umono is already running (PID: %d); stop it with 'umono down' first\n", inst.PID)
+		os.Exit(1)
+	}
+
+	if err := unit.Install(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	path, _ := unit.Path()
+	fmt.Printf("✅ Installed and started %s service %s\n", unit.Scope, unit.Name)
+	fmt.Printf("   Unit: %s\n", path)
+	if unit.Scope == service.User {
+		fmt.Println("   To keep it running after you log out, run: loginctl enable-linger $USER")
+	}
+}
+
+func runServiceUninstall(cmd *cobra.Command, args []string) {
+	cwd := requireProject()
+
+	unit := serviceUnit(cmd, cwd)
+	if !unit.Installed() {
+		fmt.Printf("No %s service is installed for this project\n", unit.Scope)
+		return
+	}
+
+	if unit.Scope == service.System && os.Geteuid() != 0 {
+		fmt.Fprintf(os.Stderr, "Error: removing a system service requires root; run it with sudo\n")
+		os.Exit(1)
+	}
+
+	if err := unit.Uninstall(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Removed %s service %s\n", unit.Scope, unit.Name)
+}
+
+func runServiceStatus(cmd *cobra.Command, args []string) {
+	cwd := requireProject()
+
+	unit := serviceUnit(cmd, cwd)
+	if !unit.Installed() {
+		fmt.Printf("No %s service is installed for this project\n", unit.Scope)
+		fmt.Printf("   Unit name would be: %s\n", unit.Name)
+		return
+	}
+
+	printServiceStatus(unit)
+}
+
+func printServiceStatus(unit *service.Unit) {
+	status, err := unit.Status()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if status.ActiveState == "active" {
+		fmt.Printf("✅ Umono is running as %s service %s (PID: %s)\n", unit.Scope, unit.Name, status.MainPID)
+	} else {
+		fmt.Printf("⏹️ Umono service %s is %s (%s)\n", unit.Name, status.ActiveState, status.SubState)
+	}
+	fmt.Printf("   Enabled: %s\n", status.Enabled)
+
+	journal := "journalctl"
+	if unit.Scope == service.User {
+		journal += " --user"
+	}
+	fmt.Printf("   Logs: %s -u %s\n", journal, unit.Name)
+}
+
+// managedService returns the project's installed service, which then owns
+// the instance instead of this CLI.
+func managedService(dir string) *service.Unit {
+	unit := service.Find(dir)
+	if unit != nil {
+		fmt.Printf("ℹ️  Umono runs as %s service %s; using systemctl\n", unit.Scope, unit.Name)
+	}
+	return unit
+}
+
+func runServiceAction(action func() error, done string) {
+	if err := action(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(done)
+}
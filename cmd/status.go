@@ -1,15 +1,17 @@
 package cmd
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
-	"strings"
-	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/umono-cms/cli/internal/confed"
+	"github.com/umono-cms/cli/internal/project"
+	"github.com/umono-cms/cli/internal/service"
+	"github.com/umono-cms/cli/internal/state"
+	"github.com/umono-cms/cli/internal/supervisor"
 )
 
 var statusCmd = &cobra.Command{
@@ -24,100 +26,94 @@ func init() {
 }
 
 func runStatus(cmd *cobra.Command, args []string) {
-	cwd, err := os.Getwd()
+	cwd, err := findProject()
+	if errors.Is(err, project.ErrNotFound) {
+		fmt.Println("⚠️  Not an Umono project (no umono executable found)")
+		return
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	umonoPath := filepath.Join(cwd, "umono")
-	umonoExists := true
-	if _, err := os.Stat(umonoPath); os.IsNotExist(err) {
-		umonoExists = false
-	}
-
-	if !umonoExists {
-		fmt.Println("⚠️  Not an Umono project (no umono executable found)")
-		return
-	}
+	checkInstalledCompatibility(cwd, false)
 
 	port := readPortFromEnv(cwd)
 
-	pidPath := filepath.Join(cwd, ".PID")
-	pidData, err := os.ReadFile(pidPath)
-
-	if os.IsNotExist(err) {
-		fmt.Println("⏹️ Umono is stopped")
+	if unit := service.Find(cwd); unit != nil {
+		printServiceStatus(unit)
 		if port != "" {
 			fmt.Printf("   Port: %s\n", port)
 		}
 		return
 	}
 
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to read .PID file: %v\n", err)
+	st, err := state.Load(cwd)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Error: failed to read state: %v\n", err)
 		os.Exit(1)
 	}
 
-	pidStr := strings.TrimSpace(string(pidData))
-	pid, err := strconv.Atoi(pidStr)
-	if err != nil {
-		fmt.Println("⚠️  Invalid .PID file")
-		return
-	}
-
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		fmt.Println("⏹️ Umono is stopped (stale .PID file)")
-		if port != "" {
-			fmt.Printf("   Port: %s\n", port)
+	if st == nil || !st.Alive() {
+		if st != nil {
+			fmt.Println("⏹️ Umono is stopped (stale state file)")
+		} else {
+			fmt.Println("⏹️ Umono is stopped")
 		}
-		return
-	}
-
-	if err := process.Signal(syscall.Signal(0)); err != nil {
-		fmt.Println("⏹️ Umono is stopped (stale .PID file)")
 		if port != "" {
 			fmt.Printf("   Port: %s\n", port)
 		}
+		printSupervisorStatus(cwd, 0)
 		return
 	}
 
-	fmt.Printf("✅ Umono is running (PID: %d)\n", pid)
+	fmt.Printf("✅ Umono is running (PID: %d)\n", st.PID)
+	if st.Mode != "" {
+		fmt.Printf("   Mode: %s, since %s\n", st.Mode, st.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	}
 	if port != "" {
 		fmt.Printf("   Port: %s\n", port)
 		fmt.Printf("   URL:  http://localhost:%s\n", port)
 	}
+	printSupervisorStatus(cwd, st.PID)
 }
 
-func readPortFromEnv(dir string) string {
-	envPath := filepath.Join(dir, ".env")
-	file, err := os.Open(envPath)
+// printSupervisorStatus shows what the supervisor recorded. For a running
+// instance (pid != 0) only the status of that supervisor is shown; for a
+// stopped one, how the last server exited.
+func printSupervisorStatus(dir string, pid int) {
+	status, err := supervisor.LoadStatus(supervisorStatusPath(dir))
 	if err != nil {
-		return ""
+		return
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
 
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+	if pid != 0 {
+		if status.PID != pid {
+			return
 		}
-
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
+		if status.Policy != supervisor.RestartNever {
+			limit := ""
+			if status.MaxRestarts > 0 {
+				limit = fmt.Sprintf(", max %d in a row", status.MaxRestarts)
+			}
+			fmt.Printf("   Restart policy: %s (%d restarts%s)\n", status.Policy, status.Restarts, limit)
+		}
+		if status.ServerPID == 0 {
+			fmt.Println("   ⚠️  Server is not running; waiting to restart it")
 		}
+	}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+	if status.LastExitCode != nil {
+		fmt.Printf("   Last exit: %s (%s)\n", status.LastExit, status.LastExitAt.Local().Format("2006-01-02 15:04:05"))
+	}
+}
 
-		if key == "PORT" {
-			return value
-		}
+func readPortFromEnv(dir string) string {
+	env := confed.NewEnvEditor()
+	if err := env.Read(filepath.Join(dir, ".env")); err != nil {
+		return ""
 	}
 
-	return ""
+	port, _ := env.GetValue("PORT")
+	return port
 }
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/umono-cms/cli/internal/config"
+	"github.com/umono-cms/cli/internal/receipt"
+	"github.com/umono-cms/cli/internal/state"
+	"github.com/umono-cms/cli/internal/supervisor"
+)
+
+var (
+	logFile       string
+	restartPolicy string
+	maxRestarts   int
+)
+
+// superviseCmd is started by `up -d` and is not meant to be run by hand.
+var superviseCmd = &cobra.Command{
+	Use:    "supervise",
+	Short:  "Run Umono and capture its output (used by up -d)",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	Run:    runSupervise,
+}
+
+func init() {
+	superviseCmd.Flags().StringVar(&logFile, "log-file", "", "Write output to this file")
+	superviseCmd.Flags().StringVar(&restartPolicy, "restart", "no", "Restart policy: no, on-failure or always")
+	superviseCmd.Flags().IntVar(&maxRestarts, "max-restarts", 0, "Give up after this many restarts in a row (0 for no limit)")
+	rootCmd.AddCommand(superviseCmd)
+}
+
+func runSupervise(cmd *cobra.Command, args []string) {
+	cwd := requireProject()
+
+	opts, err := supervisorOptions(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = supervisor.Run(opts)
+	state.RemoveIf(cwd, os.Getpid())
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+			os.Exit(exitErr.ExitCode())
+		}
+		os.Exit(1)
+	}
+}
+
+func supervisorOptions(projectPath string) (supervisor.Options, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return supervisor.Options{}, err
+	}
+
+	policy, err := supervisor.ParsePolicy(restartPolicy)
+	if err != nil {
+		return supervisor.Options{}, err
+	}
+
+	opts := supervisor.Options{
+		Dir:         projectPath,
+		Binary:      filepath.Join(projectPath, "umono"),
+		Log:         cfg.Logs.Options(projectPath),
+		Restart:     policy,
+		MaxRestarts: maxRestarts,
+		StatusPath:  supervisorStatusPath(projectPath),
+	}
+
+	if logFile != "" {
+		opts.Log.Path = logFile
+		if !filepath.IsAbs(logFile) {
+			opts.Log.Path = filepath.Join(projectPath, logFile)
+		}
+	}
+
+	return opts, nil
+}
+
+func supervisorStatusPath(projectPath string) string {
+	return filepath.Join(projectPath, receipt.Dir, supervisor.StatusFile)
+}
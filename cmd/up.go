@@ -3,109 +3,105 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"strconv"
-	"strings"
-	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
-var detach bool
+var (
+	detach          bool
+	waitForReady    bool
+	waitTimeout     time.Duration
+	healthCheckPath string
+)
 
 var upCmd = &cobra.Command{
 	Use:   "up",
 	Short: "Start Umono",
-	Long:  `Start the Umono application in the current directory. If Umono is already running, it will notify you. Use -d flag to run in background (detached mode).`,
+	Long:  `Start the Umono application in the current directory. If Umono is already running, it will notify you. In the foreground, SIGINT, SIGTERM and SIGHUP are passed on to Umono and the command exits with its exit code. Use -d flag to run in background (detached mode); its output is written to logs/umono.log and rotated by size and age, and the command waits until Umono responds over HTTP and stops it if it does not within --wait-timeout (disable with --wait=false). Use --restart=on-failure or --restart=always to have the supervisor bring a crashed instance back, with exponential backoff.`,
 	Run:   runUp,
 }
 
 func init() {
 	upCmd.Flags().BoolVarP(&detach, "detach", "d", false, "Run in background")
+	upCmd.Flags().StringVar(&restartPolicy, "restart", "no", "With -d, restart Umono when it exits: no, on-failure or always")
+	upCmd.Flags().IntVar(&maxRestarts, "max-restarts", 0, "Give up after this many restarts in a row (0 for no limit)")
+	upCmd.Flags().BoolVar(&waitForReady, "wait", true, "With -d, wait until Umono responds over HTTP")
+	upCmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 30*time.Second, "How long to wait for Umono to respond")
+	upCmd.Flags().StringVar(&healthCheckPath, "health-path", "/", "Path requested to check that Umono is ready")
+	upCmd.Flags().StringVar(&logFile, "log-file", "", "Write detached output to this file (default logs/umono.log)")
 	rootCmd.AddCommand(upCmd)
 }
 
 func runUp(cmd *cobra.Command, args []string) {
-	cwd, err := os.Getwd()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
-		os.Exit(1)
-	}
+	cwd := requireProject()
 
-	umonoPath := filepath.Join(cwd, "umono")
-	if _, err := os.Stat(umonoPath); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "Error: umono executable not found in current directory\n")
+	if _, err := executablePath(cwd); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	if info, err := os.Stat(umonoPath); err == nil {
-		if info.Mode()&0o111 == 0 {
-			fmt.Fprintf(os.Stderr, "Error: umono file exists but is not executable\n")
-			os.Exit(1)
-		}
+	requireDetachedForRestart(cmd, detach)
+
+	checkInstalledCompatibility(cwd, true)
+
+	if unit := managedService(cwd); unit != nil {
+		runServiceAction(unit.Start, "Umono service started")
+		return
 	}
 
-	pidPath := filepath.Join(cwd, ".PID")
-	if pidData, err := os.ReadFile(pidPath); err == nil {
-		pidStr := strings.TrimSpace(string(pidData))
-		if pid, err := strconv.Atoi(pidStr); err == nil {
-			process, err := os.FindProcess(pid)
-			if err == nil {
-				if err := process.Signal(syscall.Signal(0)); err == nil {
-					fmt.Println("Umono is already running (PID:", pid, ")")
-					os.Exit(0)
-				}
-			}
-		}
-		os.Remove(pidPath)
+	if inst, err := runningInstance(cwd); err == nil {
+		fmt.Println("Umono is already running (PID:", inst.PID, ")")
+		os.Exit(0)
 	}
 
 	if detach {
-		execCmd := exec.Command(umonoPath)
-		execCmd.Dir = cwd
-		execCmd.Stdout = nil
-		execCmd.Stderr = nil
-		execCmd.Stdin = nil
-
-		execCmd.SysProcAttr = &syscall.SysProcAttr{
-			Setpgid: true,
-		}
-
-		if err := execCmd.Start(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: failed to start umono: %v\n", err)
+		inst, err := startDetached(cwd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
-		pid := execCmd.Process.Pid
-		if err := os.WriteFile(pidPath, []byte(strconv.Itoa(pid)), 0o644); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to write PID file: %v\n", err)
+		port := readPortFromEnv(cwd)
+		if waitForReady && port != "" {
+			fmt.Printf("⏳ Waiting for Umono to respond on port %s...\n", port)
+			if err := waitReady(inst, port, healthPath(cmd), waitTimeout); err != nil {
+				err = startupError(cwd, err)
+				abandonStart(cwd, inst)
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
 		}
 
-		fmt.Println("Umono started in background (PID:", pid, ")")
-	} else {
-		execCmd := exec.Command(umonoPath)
-		execCmd.Dir = cwd
-		execCmd.Stdout = os.Stdout
-		execCmd.Stderr = os.Stderr
-		execCmd.Stdin = os.Stdin
-
-		if err := execCmd.Start(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: failed to start umono: %v\n", err)
-			os.Exit(1)
+		fmt.Println("Umono started in background (PID:", inst.PID, ")")
+		if port != "" {
+			fmt.Printf("   URL:  http://localhost:%s\n", port)
 		}
-
-		pid := execCmd.Process.Pid
-		if err := os.WriteFile(pidPath, []byte(strconv.Itoa(pid)), 0o644); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to write PID file: %v\n", err)
+		if opts, err := supervisorOptions(cwd); err == nil {
+			fmt.Printf("   Logs: %s\n", displayPath(cwd, opts.Log.Path))
 		}
+		return
+	}
 
-		fmt.Println("Umono started (PID:", pid, ")")
+	err := runForeground(cwd, func(inst *instance) {
+		fmt.Println("Umono started (PID:", inst.PID, ")")
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Umono exited with error: %v\n", err)
+	}
+	os.Exit(exitCode(err))
+}
 
-		if err := execCmd.Wait(); err != nil {
-			fmt.Fprintf(os.Stderr, "Umono exited with error: %v\n", err)
+// requireDetachedForRestart rejects a restart policy for an instance that is
+// not supervised: only the detached supervisor restarts Umono.
+func requireDetachedForRestart(cmd *cobra.Command, detached bool) {
+	if detached {
+		return
+	}
+	for _, name := range []string{"restart", "max-restarts"} {
+		if cmd.Flags().Changed(name) {
+			fmt.Fprintf(os.Stderr, "Error: --%s only applies to a detached instance; add -d\n", name)
+			os.Exit(1)
 		}
-
-		os.Remove(pidPath)
 	}
 }
@@ -20,6 +20,9 @@ This command will:
 
 Your database (umono.db) and configuration (.env) will be preserved.
 
+Use --strict to refuse releases without signed checksums or a manifest.
+Use --version to pin a release, e.g. --version "~1.4".
+
 Example:
   cd my-project
   umono upgrade`,
@@ -27,19 +30,26 @@ Example:
 }
 
 func init() {
+	upgradeCmd.Flags().BoolVar(&strict, "strict", false, "Refuse releases that cannot be fully verified")
+	upgradeCmd.Flags().StringVar(&releaseVersion, "version", "", "Install the newest release matching a version constraint (e.g. \"^1.2\", \">=1.2 <2\")")
 	rootCmd.AddCommand(upgradeCmd)
 }
 
 func runUpgrade(cmd *cobra.Command, args []string) {
-	wd, err := os.Getwd()
+	wd := requireProject()
+
+	strictVerification, err := strictMode(cmd)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
 	fmt.Println("🔄 Checking for updates...")
 
-	err = project.Upgrade(wd)
+	err = project.Upgrade(wd, project.UpgradeOptions{
+		Strict:  strictVerification,
+		Version: releaseVersion,
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/umono-cms/cli/internal/project"
+)
+
+var verifyOffline bool
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the installation for tampering or drift",
+	Long: `Check that the files installed in the current project still match the release they came from.
+
+This command will:
+  - Recompute the hash of the umono binary and every other shipped file
+  - Compare them against the install receipt recorded by 'create' and 'upgrade'
+  - Check the recorded release archive against the release's checksums.txt
+
+Modified, missing and extra files are reported. Runtime data is not counted
+as extra: .env, .umono/, logs/, uploads/, the database named by DSN and the
+configured log file. The command exits with a
+non-zero status when anything does not match, so it can be used in monitoring.
+
+Example:
+  cd my-project
+  umono verify`,
+	Run: runVerify,
+}
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyOffline, "offline", false, "Skip checking the release's checksums.txt")
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func runVerify(cmd *cobra.Command, args []string) {
+	cwd := requireProject()
+
+	checkInstalledCompatibility(cwd, false)
+
+	report, err := project.Verify(cwd, project.VerifyOptions{Offline: verifyOffline})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("🔍 Verifying installation of %s\n", report.Version)
+	if report.BinaryOnly {
+		fmt.Println("   ℹ️  Only the umono binary is recorded for this project; other files are not checked")
+	}
+
+	for _, name := range report.Modified {
+		fmt.Printf("   modified: %s\n", name)
+	}
+	for _, name := range report.Missing {
+		fmt.Printf("   missing:  %s\n", name)
+	}
+	for _, name := range report.Extra {
+		fmt.Printf("   extra:    %s\n", name)
+	}
+	if report.ReleaseError != nil {
+		fmt.Printf("   release:  %v\n", report.ReleaseError)
+	}
+
+	if !report.OK() {
+		fmt.Println("❌ Installation does not match the release")
+		os.Exit(1)
+	}
+
+	if report.BinaryOnly {
+		fmt.Println("✅ The umono binary matches the release")
+		return
+	}
+	fmt.Println("✅ Installation matches the release")
+}
@@ -3,14 +3,45 @@ package checksum
 import (
 	"bufio"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
 	"os"
+	"regexp"
 	"strings"
 )
 
+type Algorithm string
+
+const (
+	SHA256 Algorithm = "sha256"
+	SHA512 Algorithm = "sha512"
+)
+
+func (a Algorithm) new() hash.Hash {
+	if a == SHA512 {
+		return sha512.New()
+	}
+	return sha256.New()
+}
+
+func algorithmForHash(hash string) (Algorithm, bool) {
+	if _, err := hex.DecodeString(hash); err != nil {
+		return "", false
+	}
+
+	switch len(hash) {
+	case sha256.Size * 2:
+		return SHA256, true
+	case sha512.Size * 2:
+		return SHA512, true
+	}
+	return "", false
+}
+
 type Verifier struct {
 	checksums map[string]string
 }
@@ -45,51 +76,109 @@ func (v *Verifier) LoadFromFile(path string) error {
 	return v.parseChecksums(file)
 }
 
+func (v *Verifier) Load(r io.Reader) error {
+	return v.parseChecksums(r)
+}
+
+var (
+	gnuLine = regexp.MustCompile(`^([0-9A-Fa-f]+)\s+\*?(.+)$`)
+	bsdLine = regexp.MustCompile(`^(SHA-?256|SHA-?512)\s*\((.+)\)\s*=\s*([0-9A-Fa-f]+)$`)
+)
+
+// parseChecksums replaces the loaded checksums with those in r. On error the
+// previously loaded ones are kept.
 func (v *Verifier) parseChecksums(r io.Reader) error {
+	parseErr := &ParseError{}
+	checksums := make(map[string]string)
+
+	lineNum := 0
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
+		lineNum++
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
 
-		parts := strings.Fields(line)
-		if len(parts) != 2 {
+		filename, hash, err := parseLine(line)
+		if err != nil {
+			parseErr.add(lineNum, err.Error())
 			continue
 		}
 
-		hash := parts[0]
-		filename := parts[1]
-
-		if len(hash) != 64 {
+		if existing, ok := checksums[filename]; ok && existing != hash {
+			parseErr.add(lineNum, fmt.Sprintf("conflicting checksum for %s", filename))
 			continue
 		}
 
-		v.checksums[filename] = strings.ToLower(hash)
+		checksums[filename] = hash
 	}
 
 	if err := scanner.Err(); err != nil {
 		return fmt.Errorf("failed to parse checksums: %w", err)
 	}
 
-	if len(v.checksums) == 0 {
+	if len(parseErr.Problems) > 0 {
+		return parseErr
+	}
+
+	if len(checksums) == 0 {
 		return fmt.Errorf("no valid checksums found")
 	}
 
+	v.checksums = checksums
 	return nil
 }
 
+// parseLine accepts GNU coreutils lines ("<hash>  <file>", or "<hash> *<file>"
+// in binary mode) and BSD tagged lines ("SHA256 (<file>) = <hash>").
+func parseLine(line string) (string, string, error) {
+	if m := bsdLine.FindStringSubmatch(line); m != nil {
+		tag := Algorithm(strings.ToLower(strings.ReplaceAll(m[1], "-", "")))
+		filename, hash := m[2], strings.ToLower(m[3])
+
+		alg, ok := algorithmForHash(hash)
+		if !ok {
+			return "", "", fmt.Errorf("invalid hash length %d for %s", len(hash), filename)
+		}
+		if alg != tag {
+			return "", "", fmt.Errorf("%s hash has the length of %s for %s", tag, alg, filename)
+		}
+		return filename, hash, nil
+	}
+
+	if m := gnuLine.FindStringSubmatch(line); m != nil {
+		hash, filename := strings.ToLower(m[1]), m[2]
+		if _, ok := algorithmForHash(hash); !ok {
+			return "", "", fmt.Errorf("invalid hash length %d for %s", len(hash), filename)
+		}
+		return filename, hash, nil
+	}
+
+	return "", "", fmt.Errorf("unrecognized checksum line")
+}
+
 func (v *Verifier) VerifyFile(filepath, filename string) error {
-	expectedHash, ok := v.checksums[filename]
-	if !ok {
+	if !v.HasChecksum(filename) {
 		return fmt.Errorf("no checksum found for %s", filename)
 	}
 
-	actualHash, err := calculateFileSHA256(filepath)
+	alg, _ := v.Algorithm(filename)
+	actualHash, err := calculateFileHash(filepath, alg)
 	if err != nil {
 		return fmt.Errorf("failed to calculate checksum: %w", err)
 	}
 
+	return v.VerifyHash(filename, actualHash)
+}
+
+func (v *Verifier) VerifyHash(filename, actualHash string) error {
+	expectedHash, ok := v.checksums[filename]
+	if !ok {
+		return fmt.Errorf("no checksum found for %s", filename)
+	}
+
+	actualHash = strings.ToLower(actualHash)
 	if actualHash != expectedHash {
 		return &ChecksumMismatchError{
 			Filename: filename,
@@ -102,24 +191,19 @@ func (v *Verifier) VerifyFile(filepath, filename string) error {
 }
 
 func (v *Verifier) VerifyReader(r io.Reader, filename string) ([]byte, error) {
-	expectedHash, ok := v.checksums[filename]
-	if !ok {
+	if !v.HasChecksum(filename) {
 		return nil, fmt.Errorf("no checksum found for %s", filename)
 	}
 
-	hasher := sha256.New()
-	data, err := io.ReadAll(io.TeeReader(r, hasher))
+	alg, _ := v.Algorithm(filename)
+	hr := NewHashingReaderWithAlgorithm(r, alg)
+	data, err := io.ReadAll(hr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read data: %w", err)
 	}
 
-	actualHash := hex.EncodeToString(hasher.Sum(nil))
-	if actualHash != expectedHash {
-		return nil, &ChecksumMismatchError{
-			Filename: filename,
-			Expected: expectedHash,
-			Actual:   actualHash,
-		}
+	if err := v.VerifyHash(filename, hr.Sum()); err != nil {
+		return nil, err
 	}
 
 	return data, nil
@@ -135,6 +219,43 @@ func (v *Verifier) GetChecksum(filename string) (string, bool) {
 	return hash, ok
 }
 
+// Algorithm reports which hash function produced the checksum for filename.
+// Unknown files default to SHA-256.
+func (v *Verifier) Algorithm(filename string) (Algorithm, bool) {
+	hash, ok := v.checksums[filename]
+	if !ok {
+		return SHA256, false
+	}
+	return algorithmForHash(hash)
+}
+
+// HashingReader hashes everything read through it, so an archive can be
+// hashed while it is being extracted.
+type HashingReader struct {
+	r      io.Reader
+	hasher hash.Hash
+}
+
+func NewHashingReader(r io.Reader) *HashingReader {
+	return NewHashingReaderWithAlgorithm(r, SHA256)
+}
+
+func NewHashingReaderWithAlgorithm(r io.Reader, alg Algorithm) *HashingReader {
+	hasher := alg.new()
+	return &HashingReader{
+		r:      io.TeeReader(r, hasher),
+		hasher: hasher,
+	}
+}
+
+func (h *HashingReader) Read(p []byte) (int, error) {
+	return h.r.Read(p)
+}
+
+func (h *HashingReader) Sum() string {
+	return hex.EncodeToString(h.hasher.Sum(nil))
+}
+
 type ChecksumMismatchError struct {
 	Filename string
 	Expected string
@@ -145,14 +266,30 @@ func (e *ChecksumMismatchError) Error() string {
 	return fmt.Sprintf("checksum mismatch for %s: expected %s, got %s", e.Filename, e.Expected, e.Actual)
 }
 
+type ParseError struct {
+	Problems []string
+}
+
+func (e *ParseError) add(line int, problem string) {
+	e.Problems = append(e.Problems, fmt.Sprintf("line %d: %s", line, problem))
+}
+
+func (e *ParseError) Error() string {
+	return "invalid checksums file:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
 func calculateFileSHA256(filepath string) (string, error) {
+	return calculateFileHash(filepath, SHA256)
+}
+
+func calculateFileHash(filepath string, alg Algorithm) (string, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
 		return "", err
 	}
 	defer file.Close()
 
-	hasher := sha256.New()
+	hasher := alg.new()
 	if _, err := io.Copy(hasher, file); err != nil {
 		return "", err
 	}
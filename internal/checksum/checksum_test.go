@@ -3,7 +3,9 @@ package checksum
 import (
 	"bytes"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -49,6 +51,69 @@ e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  file.tar.gz
 			expected: nil,
 			wantErr:  true,
 		},
+		{
+			name:  "binary mode entry",
+			input: `e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855 *umono.tar.gz`,
+			expected: map[string]string{
+				"umono.tar.gz": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			},
+			wantErr: false,
+		},
+		{
+			name:  "filename with spaces",
+			input: `e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  my release.tar.gz`,
+			expected: map[string]string{
+				"my release.tar.gz": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			},
+			wantErr: false,
+		},
+		{
+			name: "bsd style",
+			input: `SHA256 (umono.tar.gz) = E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855
+SHA512 (umono.zip) = cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e`,
+			expected: map[string]string{
+				"umono.tar.gz": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+				"umono.zip":    "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e",
+			},
+			wantErr: false,
+		},
+		{
+			name:  "sha512",
+			input: `cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e  umono.tar.gz`,
+			expected: map[string]string{
+				"umono.tar.gz": "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e",
+			},
+			wantErr: false,
+		},
+		{
+			name:     "bsd tag does not match hash length",
+			input:    `SHA512 (umono.tar.gz) = e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855`,
+			expected: nil,
+			wantErr:  true,
+		},
+		{
+			name: "unparseable line alongside valid ones",
+			input: `e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  file.tar.gz
+this is not a checksum`,
+			expected: nil,
+			wantErr:  true,
+		},
+		{
+			name: "identical duplicate",
+			input: `e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  file.tar.gz
+e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855 *file.tar.gz`,
+			expected: map[string]string{
+				"file.tar.gz": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			},
+			wantErr: false,
+		},
+		{
+			name: "conflicting duplicate",
+			input: `e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  file.tar.gz
+a948904f2f0f479b8f8564cbf12dac6b0c7e0e5f5e8e8e8e8e8e8e8e8e8e8e8e  file.tar.gz`,
+			expected: nil,
+			wantErr:  true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -77,6 +142,27 @@ e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  file.tar.gz
 	}
 }
 
+func TestVerifier_LoadReplacesChecksums(t *testing.T) {
+	first := `e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  file.tar.gz
+e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  old.tar.gz`
+	second := `a948904f2f0f479b8f8564cbf12dac6b0c7e0e5f5e8e8e8e8e8e8e8e8e8e8e8e  file.tar.gz`
+
+	v := NewVerifier()
+	if err := v.Load(bytes.NewBufferString(first)); err != nil {
+		t.Fatalf("Load(first) error = %v", err)
+	}
+	if err := v.Load(bytes.NewBufferString(second)); err != nil {
+		t.Fatalf("Load(second) error = %v", err)
+	}
+
+	if got := v.checksums["file.tar.gz"]; got != "a948904f2f0f479b8f8564cbf12dac6b0c7e0e5f5e8e8e8e8e8e8e8e8e8e8e8e" {
+		t.Errorf("checksum for file.tar.gz = %s, want the second one", got)
+	}
+	if _, ok := v.checksums["old.tar.gz"]; ok {
+		t.Error("checksum for old.tar.gz kept from the first load")
+	}
+}
+
 func TestVerifier_VerifyFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	testFile := filepath.Join(tmpDir, "test.txt")
@@ -193,3 +279,88 @@ func TestChecksumMismatchError(t *testing.T) {
 func contains(s, substr string) bool {
 	return bytes.Contains([]byte(s), []byte(substr))
 }
+
+func TestHashingReader(t *testing.T) {
+	testContent := []byte("streamed archive bytes")
+
+	hasher := sha256.New()
+	hasher.Write(testContent)
+	expectedHash := hex.EncodeToString(hasher.Sum(nil))
+
+	hr := NewHashingReader(bytes.NewReader(testContent))
+	data, err := io.ReadAll(hr)
+	if err != nil {
+		t.Fatalf("ReadAll() unexpected error: %v", err)
+	}
+	if !bytes.Equal(data, testContent) {
+		t.Error("HashingReader returned data doesn't match input")
+	}
+
+	if got := hr.Sum(); got != expectedHash {
+		t.Errorf("Sum() = %s, want %s", got, expectedHash)
+	}
+}
+
+func TestVerifier_VerifyHash(t *testing.T) {
+	hash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	v := NewVerifier()
+	v.checksums["empty.tar.gz"] = hash
+
+	if err := v.VerifyHash("empty.tar.gz", hash); err != nil {
+		t.Errorf("VerifyHash() unexpected error: %v", err)
+	}
+
+	if err := v.VerifyHash("empty.tar.gz", "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"); err != nil {
+		t.Errorf("VerifyHash() should accept upper-case hashes, got: %v", err)
+	}
+
+	err := v.VerifyHash("empty.tar.gz", "0000000000000000000000000000000000000000000000000000000000000000")
+	if _, ok := err.(*ChecksumMismatchError); !ok {
+		t.Errorf("VerifyHash() expected ChecksumMismatchError, got %T", err)
+	}
+
+	if err := v.VerifyHash("missing.tar.gz", hash); err == nil {
+		t.Error("VerifyHash() expected error for missing checksum")
+	}
+}
+
+func TestParseError_ReportsLines(t *testing.T) {
+	input := `e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  file.tar.gz
+garbage
+a948904f2f0f479b8f8564cbf12dac6b0c7e0e5f5e8e8e8e8e8e8e8e8e8e8e8e  file.tar.gz`
+
+	v := NewVerifier()
+	err := v.parseChecksums(bytes.NewBufferString(input))
+
+	parseErr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("parseChecksums() expected ParseError, got %T", err)
+	}
+
+	if len(parseErr.Problems) != 2 {
+		t.Fatalf("ParseError has %d problems, want 2: %v", len(parseErr.Problems), parseErr.Problems)
+	}
+	if !contains(parseErr.Problems[0], "line 2") || !contains(parseErr.Problems[1], "line 3") {
+		t.Errorf("ParseError problems missing line numbers: %v", parseErr.Problems)
+	}
+}
+
+func TestVerifier_VerifyReader_SHA512(t *testing.T) {
+	testContent := []byte("sha512 content")
+
+	hasher := sha512.New()
+	hasher.Write(testContent)
+	expectedHash := hex.EncodeToString(hasher.Sum(nil))
+
+	v := NewVerifier()
+	v.checksums["data.bin"] = expectedHash
+
+	if alg, _ := v.Algorithm("data.bin"); alg != SHA512 {
+		t.Errorf("Algorithm() = %s, want %s", alg, SHA512)
+	}
+
+	if _, err := v.VerifyReader(bytes.NewReader(testContent), "data.bin"); err != nil {
+		t.Errorf("VerifyReader() unexpected error: %v", err)
+	}
+}
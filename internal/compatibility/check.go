@@ -2,23 +2,62 @@ package compatibility
 
 import (
 	"fmt"
-	"strconv"
+	"os"
+	"runtime"
 	"strings"
 
 	"github.com/umono-cms/cli/internal/download"
+	"github.com/umono-cms/cli/internal/receipt"
 	"github.com/umono-cms/cli/internal/version"
 )
 
 const CLIUpgradeURL = "https://umono.io/cli"
 
+type Environment struct {
+	CLIVersion string
+	OS         string
+	Arch       string
+
+	// Project describes an existing installation. It is nil when a new
+	// project is being created.
+	Project *ProjectEnvironment
+}
+
+type ProjectEnvironment struct {
+	EnvKeys []string
+	// SchemaVersion is the data schema version of the installed release, 0
+	// when it is not known.
+	SchemaVersion int
+}
+
+func CurrentEnvironment() Environment {
+	return Environment{
+		CLIVersion: version.Version,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+	}
+}
+
+type Violation struct {
+	Reason string
+	Hint   string
+}
+
 type CheckResult struct {
-	Compatible    bool
-	CLIVersion    string
-	MinCLIVersion string
-	UmonoVersion  string
+	Compatible      bool
+	CLIVersion      string
+	MinCLIVersion   string
+	MaxCLIVersion   string
+	UmonoVersion    string
+	ManifestMissing bool
+	Manifest        *download.Manifest
+
+	Violations        []Violation
+	Warnings          []string
+	PendingMigrations []download.Migration
 }
 
-func Check(client *download.Client) (*CheckResult, error) {
+func Check(client *download.Client, env Environment) (*CheckResult, error) {
 	manifest, err := client.GetManifest()
 	if err != nil {
 		return nil, fmt.Errorf("failed to check compatibility: %w", err)
@@ -29,70 +68,178 @@ func Check(client *download.Client) (*CheckResult, error) {
 		return nil, fmt.Errorf("failed to get release info: %w", err)
 	}
 
-	compatible := isVersionCompatible(version.Version, manifest.MinCLIVersion)
-
-	return &CheckResult{
-		Compatible:    compatible,
-		CLIVersion:    version.Version,
-		MinCLIVersion: manifest.MinCLIVersion,
-		UmonoVersion:  releaseInfo.Version,
-	}, nil
+	return Evaluate(manifest, releaseInfo.Version, env), nil
 }
 
-func CheckForVersion(client *download.Client, umonoVersion string) (*CheckResult, error) {
+func CheckForVersion(client *download.Client, umonoVersion string, env Environment) (*CheckResult, error) {
 	manifest, err := client.GetManifestForVersion(umonoVersion)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check compatibility: %w", err)
 	}
 
-	compatible := isVersionCompatible(version.Version, manifest.MinCLIVersion)
+	return Evaluate(manifest, umonoVersion, env), nil
+}
+
+// CheckInstalled evaluates the CLI against the manifest recorded when the
+// project's current release was installed. It returns nil when the project
+// has no recorded manifest.
+func CheckInstalled(projectPath string) (*CheckResult, error) {
+	rec, err := receipt.Load(projectPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	return &CheckResult{
-		Compatible:    compatible,
-		CLIVersion:    version.Version,
-		MinCLIVersion: manifest.MinCLIVersion,
-		UmonoVersion:  umonoVersion,
-	}, nil
+	if rec.Manifest == nil {
+		return nil, nil
+	}
+
+	return Evaluate(rec.Manifest, rec.Version, CurrentEnvironment()), nil
 }
 
-func FormatIncompatibleError(result *CheckResult) string {
-	return fmt.Sprintf(`❌ CLI version incompatible
+func Evaluate(manifest *download.Manifest, umonoVersion string, env Environment) *CheckResult {
+	result := &CheckResult{
+		CLIVersion:      env.CLIVersion,
+		MinCLIVersion:   manifest.MinCLIVersion,
+		MaxCLIVersion:   manifest.MaxCLIVersion,
+		UmonoVersion:    umonoVersion,
+		ManifestMissing: manifest.Missing,
+		Manifest:        manifest,
+	}
 
-Your CLI version:     %s
-Required CLI version: %s (minimum)
-Umono version:        %s
+	result.Violations = append(result.Violations, evaluateCLIVersion(env.CLIVersion, manifest.MinCLIVersion, manifest.MaxCLIVersion)...)
+
+	if !platformSupported(manifest.Platforms, env.OS, env.Arch) {
+		result.Violations = append(result.Violations, Violation{
+			Reason: fmt.Sprintf("platform %s/%s is not supported (supported: %s)", env.OS, env.Arch, strings.Join(manifest.Platforms, ", ")),
+		})
+	}
+
+	if env.Project != nil {
+		evaluateProject(result, manifest, env.Project)
+	}
 
-Please upgrade your CLI to install this version of Umono:
-  → %s
-`, result.CLIVersion, result.MinCLIVersion, result.UmonoVersion, CLIUpgradeURL)
+	result.Compatible = len(result.Violations) == 0
+
+	return result
 }
 
-func isVersionCompatible(cliVersion, minVersion string) bool {
-	cliParts := parseVersion(cliVersion)
-	minParts := parseVersion(minVersion)
+func evaluateCLIVersion(cliVersion, minVersion, maxVersion string) []Violation {
+	cli, err := ParseVersion(cliVersion)
+	if err != nil {
+		return []Violation{{Reason: fmt.Sprintf("CLI version %q is not a valid version: %v", cliVersion, err)}}
+	}
+
+	var violations []Violation
+
+	if minVersion != "" {
+		min, err := ParseVersion(minVersion)
+		switch {
+		case err != nil:
+			violations = append(violations, Violation{Reason: fmt.Sprintf("manifest min_cli_version is invalid: %v", err)})
+		case cli.LessThan(min):
+			violations = append(violations, Violation{
+				Reason: fmt.Sprintf("CLI %s is older than the minimum supported version %s", cliVersion, minVersion),
+				Hint:   "Upgrade your CLI: " + CLIUpgradeURL,
+			})
+		}
+	}
 
-	for i := 0; i < 3; i++ {
-		if cliParts[i] > minParts[i] {
-			return true
+	if maxVersion != "" {
+		max, err := ParseVersion(maxVersion)
+		switch {
+		case err != nil:
+			violations = append(violations, Violation{Reason: fmt.Sprintf("manifest max_cli_version is invalid: %v", err)})
+		case max.LessThan(cli):
+			violations = append(violations, Violation{
+				Reason: fmt.Sprintf("CLI %s is newer than the maximum supported version %s", cliVersion, maxVersion),
+				Hint:   "Install an older CLI or a newer Umono release",
+			})
 		}
-		if cliParts[i] < minParts[i] {
-			return false
+	}
+
+	return violations
+}
+
+func evaluateProject(result *CheckResult, manifest *download.Manifest, project *ProjectEnvironment) {
+	present := make(map[string]bool)
+	for _, key := range project.EnvKeys {
+		present[key] = true
+	}
+
+	for _, key := range manifest.RequiredEnv {
+		if !present[key] {
+			result.Violations = append(result.Violations, Violation{
+				Reason: fmt.Sprintf("required setting %s is missing from .env", key),
+				Hint:   fmt.Sprintf("Add %s to .env (see .env.example)", key),
+			})
+		}
+	}
+
+	for _, key := range manifest.DeprecatedEnv {
+		if present[key] {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("setting %s in .env is deprecated", key))
 		}
 	}
 
-	return true
+	if project.SchemaVersion == 0 {
+		return
+	}
+
+	if target := manifest.SchemaVersion(); target > 0 && target < project.SchemaVersion {
+		result.Violations = append(result.Violations, Violation{
+			Reason: fmt.Sprintf("release data schema %d is older than the installed schema %d", target, project.SchemaVersion),
+			Hint:   "Downgrading would leave the database in a newer format than the release understands",
+		})
+	}
+
+	for _, migration := range manifest.Migrations {
+		if migration.SchemaVersion > project.SchemaVersion {
+			result.PendingMigrations = append(result.PendingMigrations, migration)
+		}
+	}
 }
 
-func parseVersion(v string) [3]int {
-	v = strings.TrimPrefix(v, "v")
-	parts := strings.Split(v, ".")
+func platformSupported(platforms []string, goos, goarch string) bool {
+	if len(platforms) == 0 {
+		return true
+	}
 
-	var result [3]int
-	for i := 0; i < 3 && i < len(parts); i++ {
-		numStr := strings.Split(parts[i], "-")[0]
-		num, _ := strconv.Atoi(numStr)
-		result[i] = num
+	for _, platform := range platforms {
+		osName, arch, hasArch := strings.Cut(platform, "/")
+		if osName == goos && (!hasArch || arch == "*" || arch == goarch) {
+			return true
+		}
 	}
 
-	return result
+	return false
+}
+
+func FormatIncompatibleError(result *CheckResult) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `❌ Umono %s is incompatible
+
+Your CLI version:     %s
+Required CLI version: %s
+
+`, result.UmonoVersion, result.CLIVersion, formatVersionRange(result.MinCLIVersion, result.MaxCLIVersion))
+
+	for _, v := range result.Violations {
+		fmt.Fprintf(&b, "  • %s\n", v.Reason)
+		if v.Hint != "" {
+			fmt.Fprintf(&b, "    → %s\n", v.Hint)
+		}
+	}
+
+	return b.String()
+}
+
+func formatVersionRange(min, max string) string {
+	if max == "" {
+		return min + " (minimum)"
+	}
+	return min + " to " + max
 }
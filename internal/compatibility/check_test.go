@@ -1,8 +1,13 @@
 package compatibility
 
-import "testing"
+import (
+	"testing"
 
-func TestIsVersionCompatible(t *testing.T) {
+	"github.com/umono-cms/cli/internal/download"
+	"github.com/umono-cms/cli/internal/receipt"
+)
+
+func TestConstraint_Minimum(t *testing.T) {
 	tests := []struct {
 		name       string
 		cliVersion string
@@ -24,14 +29,27 @@ func TestIsVersionCompatible(t *testing.T) {
 
 		{"min version 0.0.0", "0.1.0", "0.0.0", true},
 		{"both 0.0.0", "0.0.0", "0.0.0", true},
+
+		{"prerelease older than release", "1.2.0-beta.1", "1.2.0", false},
+		{"release newer than prerelease", "1.2.0", "1.2.0-beta.1", true},
+		{"prerelease ordering", "1.2.0-beta.2", "1.2.0-beta.11", false},
+		{"numeric before alphanumeric", "1.2.0-rc.1", "1.2.0-1", true},
+		{"build metadata ignored", "1.2.0+build.5", "1.2.0+build.9", true},
+		{"prerelease of newer patch needs opting in", "1.2.1-alpha", "1.2.0", false},
+		{"invalid cli version", "latest", "0.1.0", false},
+		{"invalid min version", "0.1.0", "1.0.0-", false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isVersionCompatible(tt.cliVersion, tt.minVersion)
+			got := false
+			c, err := ParseConstraint(">=" + tt.minVersion)
+			if v, vErr := ParseVersion(tt.cliVersion); err == nil && vErr == nil {
+				got = c.Check(v)
+			}
 			if got != tt.want {
-				t.Errorf("isVersionCompatible(%q, %q) = %v, want %v",
-					tt.cliVersion, tt.minVersion, got, tt.want)
+				t.Errorf(">=%s Check(%q) = %v, want %v",
+					tt.minVersion, tt.cliVersion, got, tt.want)
 			}
 		})
 	}
@@ -40,24 +58,266 @@ func TestIsVersionCompatible(t *testing.T) {
 func TestParseVersion(t *testing.T) {
 	tests := []struct {
 		input string
-		want  [3]int
+		want  string
 	}{
-		{"0.1.0", [3]int{0, 1, 0}},
-		{"v0.1.0", [3]int{0, 1, 0}},
-		{"1.2.3", [3]int{1, 2, 3}},
-		{"v1.2.3", [3]int{1, 2, 3}},
-		{"0.1", [3]int{0, 1, 0}},
-		{"1", [3]int{1, 0, 0}},
-		{"1.0.0-beta", [3]int{1, 0, 0}},
-		{"2.1.3-rc1", [3]int{2, 1, 3}},
+		{"0.1.0", "0.1.0"},
+		{"v0.1.0", "0.1.0"},
+		{"1.2.3", "1.2.3"},
+		{"v1.2.3", "1.2.3"},
+		{"0.1", "0.1.0"},
+		{"1", "1.0.0"},
+		{"1.0.0-beta", "1.0.0-beta"},
+		{"2.1.3-rc1", "2.1.3-rc1"},
+		{"1.2.0-beta.1", "1.2.0-beta.1"},
+		{"1.2.0-x-y.0.a1", "1.2.0-x-y.0.a1"},
+		{"1.2.0+build.5", "1.2.0+build.5"},
+		{"1.2.0-rc.1+sha.abc123", "1.2.0-rc.1+sha.abc123"},
+		{"1.2.0+001", "1.2.0+001"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			got := parseVersion(tt.input)
-			if got != tt.want {
-				t.Errorf("parseVersion(%q) = %v, want %v", tt.input, got, tt.want)
+			got, err := ParseVersion(tt.input)
+			if err != nil {
+				t.Fatalf("ParseVersion(%q) unexpected error: %v", tt.input, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseVersion(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVersion_Invalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"v",
+		"latest",
+		"1.2.3.4",
+		"01.2.3",
+		"1.02.3",
+		"1.2.3-",
+		"1.2.3-beta..1",
+		"1.2.3-01",
+		"1.2.3+",
+		"1.2.3-beta_1",
+		"-1.2.3",
+	}
+
+	for _, input := range inputs {
+		if _, err := ParseVersion(input); err == nil {
+			t.Errorf("ParseVersion(%q) expected error", input)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// Ordered by precedence, from the SemVer 2.0.0 specification.
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+
+	for i := 0; i < len(ordered); i++ {
+		for j := 0; j < len(ordered); j++ {
+			a, _ := ParseVersion(ordered[i])
+			b, _ := ParseVersion(ordered[j])
+
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+
+			if got := a.Compare(b); got != want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+
+	a, _ := ParseVersion("1.0.0+build.1")
+	b, _ := ParseVersion("1.0.0+build.2")
+	if a.Compare(b) != 0 {
+		t.Error("Compare() should ignore build metadata")
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{">=1.2 <2", "1.2.0", true},
+		{">=1.2 <2", "1.9.9", true},
+		{">=1.2 <2", "2.0.0", false},
+		{">=1.2 <2", "1.1.9", false},
+		{">=1.2 <2", "2.0.0-beta.1", false},
+		{">=1.2 <2", "1.5.0-beta.1", false},
+		{">= 1.2, < 2", "1.5.0", true},
+
+		{"~1.4", "1.4.0", true},
+		{"~1.4", "1.4.9", true},
+		{"~1.4", "1.5.0", false},
+		{"~1.4.2", "1.4.1", false},
+		{"~1.4.2", "1.4.3", true},
+		{"~1", "1.9.0", true},
+		{"~1", "2.0.0", false},
+
+		{"^1.0", "1.0.0", true},
+		{"^1.0", "1.99.0", true},
+		{"^1.0", "2.0.0", false},
+		{"^1.0", "0.9.0", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+
+		{"1.2.3", "1.2.3", true},
+		{"1.2.3", "1.2.4", false},
+		{"=v1.2.3", "1.2.3", true},
+		{"1.2", "1.2.7", true},
+		{"1.2", "1.3.0", false},
+		{"!=1.2.3", "1.2.4", true},
+		{"!=1.2.3", "1.2.3", false},
+		{">1.2", "1.2.9", false},
+		{">1.2", "1.3.0", true},
+		{"<=1.2", "1.2.9", true},
+		{"<=1.2", "1.3.0", false},
+		{"*", "3.1.4", true},
+
+		{"^1.0 || ^3.0", "3.2.0", true},
+		{"^1.0 || ^3.0", "2.2.0", false},
+
+		{">=1.3.0-beta.1 <2", "1.3.0-beta.2", true},
+		{">=1.3.0-beta.1 <2", "1.3.0-alpha", false},
+		{">=1.3.0-beta.1 <2", "1.4.0-beta.2", false},
+		{">=1.3.0-beta.1 <2", "1.4.0", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			c, err := ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("ParseConstraint(%q) unexpected error: %v", tt.constraint, err)
+			}
+
+			v, err := ParseVersion(tt.version)
+			if err != nil {
+				t.Fatalf("ParseVersion(%q) unexpected error: %v", tt.version, err)
+			}
+
+			if got := c.Check(v); got != tt.want {
+				t.Errorf("%q.Check(%s) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConstraint_Invalid(t *testing.T) {
+	inputs := []string{"", ">=", ">=1.2 ||", "~latest", ">>1.0", "1.2.3.4"}
+
+	for _, input := range inputs {
+		if _, err := ParseConstraint(input); err == nil {
+			t.Errorf("ParseConstraint(%q) expected error", input)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	env := Environment{CLIVersion: "0.5.0", OS: "linux", Arch: "amd64"}
+
+	withProject := func(keys []string, schema int) Environment {
+		e := env
+		e.Project = &ProjectEnvironment{EnvKeys: keys, SchemaVersion: schema}
+		return e
+	}
+
+	migrations := []download.Migration{
+		{ID: "init", SchemaVersion: 1},
+		{ID: "add-pages", SchemaVersion: 2},
+		{ID: "add-media", SchemaVersion: 3},
+	}
+
+	tests := []struct {
+		name           string
+		manifest       download.Manifest
+		env            Environment
+		wantViolations int
+		wantWarnings   int
+		wantPending    int
+	}{
+		{"empty manifest", download.Manifest{MinCLIVersion: "0.0.0"}, env, 0, 0, 0},
+		{"cli too old", download.Manifest{MinCLIVersion: "0.6.0"}, env, 1, 0, 0},
+		{"cli too new", download.Manifest{MinCLIVersion: "0.1.0", MaxCLIVersion: "0.4.9"}, env, 1, 0, 0},
+		{"cli within range", download.Manifest{MinCLIVersion: "0.1.0", MaxCLIVersion: "0.5.0"}, env, 0, 0, 0},
+		{"platform supported", download.Manifest{Platforms: []string{"darwin/arm64", "linux/amd64"}}, env, 0, 0, 0},
+		{"platform os only", download.Manifest{Platforms: []string{"linux"}}, env, 0, 0, 0},
+		{"platform unsupported", download.Manifest{Platforms: []string{"linux/arm64"}}, env, 1, 0, 0},
+		{"required env ignored without project", download.Manifest{RequiredEnv: []string{"PORT"}}, env, 0, 0, 0},
+		{"required env present", download.Manifest{RequiredEnv: []string{"PORT"}}, withProject([]string{"PORT"}, 0), 0, 0, 0},
+		{"required env missing", download.Manifest{RequiredEnv: []string{"PORT", "DSN"}}, withProject([]string{"PORT"}, 0), 1, 0, 0},
+		{"deprecated env present", download.Manifest{DeprecatedEnv: []string{"SESSION_DRIVER"}}, withProject([]string{"SESSION_DRIVER"}, 0), 0, 1, 0},
+		{"pending migrations", download.Manifest{Migrations: migrations}, withProject(nil, 1), 0, 0, 2},
+		{"unknown schema has no pending migrations", download.Manifest{Migrations: migrations}, withProject(nil, 0), 0, 0, 0},
+		{"schema downgrade", download.Manifest{Migrations: migrations[:1]}, withProject(nil, 3), 1, 0, 0},
+		{"everything wrong", download.Manifest{MinCLIVersion: "1.0.0", Platforms: []string{"darwin"}, RequiredEnv: []string{"DSN"}}, withProject(nil, 0), 3, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Evaluate(&tt.manifest, "v1.0.0", tt.env)
+
+			if len(result.Violations) != tt.wantViolations {
+				t.Errorf("Violations = %v, want %d", result.Violations, tt.wantViolations)
+			}
+			if result.Compatible != (tt.wantViolations == 0) {
+				t.Errorf("Compatible = %v with %d violations", result.Compatible, len(result.Violations))
+			}
+			if len(result.Warnings) != tt.wantWarnings {
+				t.Errorf("Warnings = %v, want %d", result.Warnings, tt.wantWarnings)
+			}
+			if len(result.PendingMigrations) != tt.wantPending {
+				t.Errorf("PendingMigrations = %v, want %d", result.PendingMigrations, tt.wantPending)
 			}
 		})
 	}
 }
+
+func TestCheckInstalled(t *testing.T) {
+	dir := t.TempDir()
+
+	result, err := CheckInstalled(dir)
+	if err != nil || result != nil {
+		t.Fatalf("CheckInstalled() without receipt = %v, %v; want nil, nil", result, err)
+	}
+
+	rec := &receipt.Receipt{
+		Version:  "v9.0.0",
+		Manifest: &download.Manifest{MinCLIVersion: "999.0.0"},
+	}
+	if err := rec.Save(dir); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	result, err = CheckInstalled(dir)
+	if err != nil {
+		t.Fatalf("CheckInstalled() unexpected error: %v", err)
+	}
+	if result.Compatible {
+		t.Error("CheckInstalled() = compatible, want a CLI that is too old")
+	}
+	if result.UmonoVersion != "v9.0.0" {
+		t.Errorf("UmonoVersion = %s, want v9.0.0", result.UmonoVersion)
+	}
+}
@@ -0,0 +1,217 @@
+package compatibility
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint is a version range such as ">=1.2 <2", "~1.4", "^1.0" or
+// "1.2 || ^2". Space- or comma-separated comparators must all match; sets
+// separated by "||" are alternatives.
+//
+// A pre-release version only satisfies a set that names a pre-release of the
+// same major.minor.patch, so "^1.0" does not match "1.3.0-beta.1".
+type Constraint struct {
+	raw  string
+	sets [][]comparator
+}
+
+type comparator struct {
+	op      string
+	version Version
+	// implied bounds come from expanding "~", "^" and partial versions and
+	// do not opt in to pre-releases.
+	implied bool
+}
+
+var operators = []string{">=", "<=", "!=", "==", ">", "<", "=", "~", "^"}
+
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{raw: strings.TrimSpace(s)}
+	if c.raw == "" {
+		return nil, fmt.Errorf("invalid constraint: empty")
+	}
+
+	for _, alternative := range strings.Split(c.raw, "||") {
+		set, err := parseComparatorSet(alternative)
+		if err != nil {
+			return nil, fmt.Errorf("invalid constraint %q: %w", s, err)
+		}
+		c.sets = append(c.sets, set)
+	}
+
+	return c, nil
+}
+
+func parseComparatorSet(s string) ([]comparator, error) {
+	tokens := strings.Fields(strings.ReplaceAll(s, ",", " "))
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty range")
+	}
+
+	var set []comparator
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		// Allow a space between operator and version (">= 1.2").
+		if isOperator(token) {
+			if i+1 >= len(tokens) {
+				return nil, fmt.Errorf("operator %q without a version", token)
+			}
+			i++
+			token += tokens[i]
+		}
+
+		comparators, err := parseComparator(token)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, comparators...)
+	}
+
+	return set, nil
+}
+
+func isOperator(s string) bool {
+	for _, op := range operators {
+		if s == op {
+			return true
+		}
+	}
+	return false
+}
+
+func parseComparator(token string) ([]comparator, error) {
+	if token == "*" || token == "x" {
+		return nil, nil
+	}
+
+	op := ""
+	for _, candidate := range operators {
+		if strings.HasPrefix(token, candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	v, given, err := parsePartialVersion(strings.TrimPrefix(token, op))
+	if err != nil {
+		return nil, err
+	}
+
+	lower := func(op string) comparator { return comparator{op: op, version: v} }
+	bound := func(op string, major, minor, patch uint64) comparator {
+		return comparator{op: op, version: Version{Major: major, Minor: minor, Patch: patch, Prerelease: []string{"0"}}, implied: true}
+	}
+	// nextUp is the first version past the range a partial version covers,
+	// e.g. 1.3.0 for "1.2".
+	nextUp := func() comparator {
+		if given == 1 {
+			return bound("<", v.Major+1, 0, 0)
+		}
+		return bound("<", v.Major, v.Minor+1, 0)
+	}
+
+	switch op {
+	case "", "=", "==":
+		if given == 3 {
+			return []comparator{lower("=")}, nil
+		}
+		return []comparator{lower(">="), nextUp()}, nil
+
+	case "!=":
+		return []comparator{lower("!=")}, nil
+
+	case ">=":
+		return []comparator{lower(">=")}, nil
+
+	case ">":
+		if given == 3 {
+			return []comparator{lower(">")}, nil
+		}
+		next := nextUp().version
+		next.Prerelease = nil
+		return []comparator{{op: ">=", version: next}}, nil
+
+	case "<":
+		if given == 3 {
+			return []comparator{lower("<")}, nil
+		}
+		return []comparator{bound("<", v.Major, v.Minor, 0)}, nil
+
+	case "<=":
+		if given == 3 {
+			return []comparator{lower("<=")}, nil
+		}
+		return []comparator{nextUp()}, nil
+
+	case "~":
+		return []comparator{lower(">="), nextUp()}, nil
+
+	case "^":
+		switch {
+		case v.Major > 0 || given == 1:
+			return []comparator{lower(">="), bound("<", v.Major+1, 0, 0)}, nil
+		case v.Minor > 0 || given == 2:
+			return []comparator{lower(">="), bound("<", 0, v.Minor+1, 0)}, nil
+		default:
+			return []comparator{lower(">="), bound("<", 0, 0, v.Patch+1)}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown operator in %q", token)
+}
+
+func (c *Constraint) Check(v Version) bool {
+	for _, set := range c.sets {
+		if setMatches(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func setMatches(set []comparator, v Version) bool {
+	for _, comp := range set {
+		if !comp.matches(v) {
+			return false
+		}
+	}
+
+	if len(v.Prerelease) == 0 {
+		return true
+	}
+
+	for _, comp := range set {
+		if !comp.implied && len(comp.version.Prerelease) > 0 && comp.version.sameCore(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c comparator) matches(v Version) bool {
+	cmp := v.Compare(c.version)
+
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+
+	return false
+}
+
+func (c *Constraint) String() string {
+	return c.raw
+}
@@ -1,97 +1,393 @@
 package confed
 
 import (
-	"bufio"
+	"fmt"
 	"os"
+	"regexp"
 	"strings"
 )
 
+// EnvEditor reads and edits .env files. Comments, blank lines, ordering and
+// the original text of untouched entries survive a Read/Write round trip;
+// only lines for keys that are set or unset are rewritten.
+//
+// The dotenv syntax understood is:
+//
+//	KEY=value              # unquoted, inline comments after whitespace
+//	export KEY=value       # optional export prefix
+//	KEY='literal $value'   # single quotes: no escapes or interpolation
+//	KEY="a\nb ${OTHER}"    # double quotes: escapes and interpolation
+//	KEY="first line
+//	second line"           # quoted values may span lines
+//
+// $VAR, ${VAR} and ${VAR:-default} refer to keys defined earlier in the file,
+// falling back to the process environment.
 type EnvEditor struct {
-	keys     []string
-	keyValue map[string]string
+	lines []*envLine
+	eol   string
+	// trailingEOL records whether the file ended with a line break.
+	trailingEOL bool
+	// pendingBlank is set by AddBlankLine; the blank line is only written
+	// when a new key is appended after it.
+	pendingBlank bool
+}
+
+type valueKind int
+
+const (
+	kindBare valueKind = iota
+	kindSingle
+	kindDouble
+	// kindLiteral values were set through SetValue and are used verbatim.
+	kindLiteral
+)
+
+type envLine struct {
+	raw     string
+	key     string
+	export  bool
+	kind    valueKind
+	source  string
+	comment string
+	dirty   bool
+}
+
+var (
+	entryPattern = regexp.MustCompile(`^\s*(export\s+)?([A-Za-z_][A-Za-z0-9_.]*)\s*=[ \t]*(.*)$`)
+	keyPattern   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+)
+
+// ValidKey reports whether key can be written as a .env entry.
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
 }
 
 func NewEnvEditor() *EnvEditor {
 	return &EnvEditor{
-		keys:     make([]string, 0),
-		keyValue: make(map[string]string),
+		lines:       make([]*envLine, 0),
+		eol:         "\n",
+		trailingEOL: true,
 	}
 }
 
 func (e *EnvEditor) Read(path string) error {
-	file, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	e.keys = make([]string, 0)
-	e.keyValue = make(map[string]string)
+	if err := e.Parse(string(data)); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
+func (e *EnvEditor) Parse(content string) error {
+	e.lines = make([]*envLine, 0)
+	e.pendingBlank = false
 
-		if line == "" {
+	e.eol = "\n"
+	if strings.Contains(content, "\r\n") {
+		e.eol = "\r\n"
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	e.trailingEOL = content == "" || strings.HasSuffix(content, "\n")
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return nil
+	}
+
+	physical := strings.Split(content, "\n")
+	for i := 0; i < len(physical); i++ {
+		m := entryPattern.FindStringSubmatch(physical[i])
+		if m == nil {
+			e.lines = append(e.lines, &envLine{raw: physical[i]})
 			continue
 		}
 
-		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+		start := i
+		value := m[3]
+
+		// Quoted values continue on the following lines until the closing
+		// quote.
+		if value != "" && (value[0] == '"' || value[0] == '\'') {
+			for {
+				if _, ok := closingQuote(value[1:], value[0]); ok {
+					break
+				}
+				if i+1 >= len(physical) {
+					return fmt.Errorf("line %d: unterminated quoted value for %s", start+1, m[2])
+				}
+				i++
+				value += "\n" + physical[i]
+			}
+		}
+
+		l := &envLine{
+			raw:    strings.Join(physical[start:i+1], "\n"),
+			key:    m[2],
+			export: m[1] != "",
+		}
+		l.kind, l.source, l.comment = parseValue(value)
+		e.lines = append(e.lines, l)
+	}
+
+	return nil
+}
+
+// closingQuote returns the index of the quote that ends s, skipping
+// backslash escapes inside double quotes.
+func closingQuote(s string, quote byte) (int, bool) {
+	for i := 0; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
 			continue
 		}
+		if s[i] == quote {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func parseValue(value string) (valueKind, string, string) {
+	if value != "" && (value[0] == '"' || value[0] == '\'') {
+		end, _ := closingQuote(value[1:], value[0])
+		kind := kindSingle
+		if value[0] == '"' {
+			kind = kindDouble
+		}
+		return kind, value[1 : end+1], value[end+2:]
+	}
+
+	// An inline comment starts at a # preceded by whitespace.
+	for i := 0; i < len(value); i++ {
+		if value[i] == '#' && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t') {
+			source := strings.TrimRight(value[:i], " \t")
+			return kindBare, source, value[len(source):]
+		}
+	}
+
+	return kindBare, strings.TrimRight(value, " \t"), ""
+}
+
+func (l *envLine) String() string {
+	if !l.dirty {
+		return l.raw
+	}
 
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
+	s := l.key + "=" + QuoteValue(l.source)
+	if l.export {
+		s = "export " + s
+	}
+	return s + l.comment
+}
+
+// QuoteValue formats value for a .env file, quoting it only when needed.
+func QuoteValue(value string) string {
+	if value == "" || isBareSafe(value) {
+		return value
+	}
+
+	if !strings.ContainsAny(value, "'\n\r") {
+		return "'" + value + "'"
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(value) + `"`
+}
+
+func isBareSafe(value string) bool {
+	for _, r := range value {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("_-.,:/@+=%", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (e *EnvEditor) lookup(key string) *envLine {
+	return e.lookupBefore(key, len(e.lines))
+}
+
+func (e *EnvEditor) lookupBefore(key string, end int) *envLine {
+	for i := end - 1; i >= 0; i-- {
+		if e.lines[i].key == key {
+			return e.lines[i]
+		}
+	}
+	return nil
+}
+
+func (e *EnvEditor) GetValue(key string) (string, bool) {
+	for i := len(e.lines) - 1; i >= 0; i-- {
+		if e.lines[i].key == key {
+			return e.evaluate(i), true
+		}
+	}
+	return "", false
+}
+
+// evaluate resolves escapes and interpolation for the entry at index i.
+// References only see keys defined before the entry, so they cannot cycle.
+func (e *EnvEditor) evaluate(i int) string {
+	l := e.lines[i]
+
+	resolve := func(name string) (string, bool) {
+		for j := i - 1; j >= 0; j-- {
+			if e.lines[j].key == name {
+				return e.evaluate(j), true
+			}
+		}
+		return os.LookupEnv(name)
+	}
+
+	switch l.kind {
+	case kindSingle, kindLiteral:
+		return l.source
+	case kindDouble:
+		return expand(l.source, true, resolve)
+	}
+	return expand(l.source, false, resolve)
+}
+
+var escapes = map[byte]string{'n': "\n", 'r': "\r", 't': "\t", '"': `"`, '\\': `\`, '$': "$"}
+
+func expand(s string, unescape bool, resolve func(string) (string, bool)) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if unescape && c == '\\' && i+1 < len(s) {
+			if r, ok := escapes[s[i+1]]; ok {
+				b.WriteString(r)
+				i++
+				continue
+			}
+		}
+
+		if c != '$' || i+1 >= len(s) {
+			b.WriteByte(c)
 			continue
 		}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+		if s[i+1] == '{' {
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				b.WriteByte(c)
+				continue
+			}
+			expr := s[i+2 : i+2+end]
+			name, fallback, hasFallback := strings.Cut(expr, ":-")
+			value, ok := resolve(name)
+			if hasFallback && (!ok || value == "") {
+				value = fallback
+			}
+			b.WriteString(value)
+			i += 2 + end
+			continue
+		}
 
-		e.keys = append(e.keys, key)
-		e.keyValue[key] = value
+		n := i + 1
+		for n < len(s) && (s[n] == '_' || s[n] >= 'a' && s[n] <= 'z' || s[n] >= 'A' && s[n] <= 'Z' || n > i+1 && s[n] >= '0' && s[n] <= '9') {
+			n++
+		}
+		if n == i+1 {
+			b.WriteByte(c)
+			continue
+		}
+		value, _ := resolve(s[i+1 : n])
+		b.WriteString(value)
+		i = n - 1
 	}
 
-	return scanner.Err()
+	return b.String()
+}
+
+func (e *EnvEditor) Keys() []string {
+	keys := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, l := range e.lines {
+		if l.key != "" && !seen[l.key] {
+			seen[l.key] = true
+			keys = append(keys, l.key)
+		}
+	}
+	return keys
 }
 
+// SetValue updates key in place, or appends it when the file does not have
+// it yet. Earlier duplicates of the key are dropped. The value is written
+// literally, quoted if needed.
 func (e *EnvEditor) SetValue(key, value string) *EnvEditor {
-	e.removeKey(key)
-	e.keys = append(e.keys, key)
-	e.keyValue[key] = value
+	l := e.lookup(key)
+	if l == nil {
+		if e.pendingBlank && len(e.lines) > 0 && e.lines[len(e.lines)-1].String() != "" {
+			e.lines = append(e.lines, &envLine{})
+		}
+		e.pendingBlank = false
+		e.trailingEOL = true
+		e.lines = append(e.lines, &envLine{key: key, kind: kindLiteral, source: value, dirty: true})
+		return e
+	}
+
+	e.removeKey(key, l)
+	// Entries that already evaluate to value keep their original text.
+	if e.evaluate(e.indexOf(l)) != value {
+		l.kind = kindLiteral
+		l.source = value
+		l.dirty = true
+	}
 	return e
 }
 
-func (e *EnvEditor) removeKey(key string) {
-	for i, k := range e.keys {
-		if k == key {
-			e.keys = append(e.keys[:i], e.keys[i+1:]...)
-			return
+func (e *EnvEditor) indexOf(l *envLine) int {
+	for i, candidate := range e.lines {
+		if candidate == l {
+			return i
 		}
 	}
+	return -1
 }
 
-func (e *EnvEditor) AddBlankLine() *EnvEditor {
-	e.keys = append(e.keys, "")
+func (e *EnvEditor) Unset(key string) *EnvEditor {
+	e.removeKey(key, nil)
 	return e
 }
 
-func (e *EnvEditor) Write(path string) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return err
+// removeKey drops every line for key except keep.
+func (e *EnvEditor) removeKey(key string, keep *envLine) {
+	lines := e.lines[:0]
+	for _, l := range e.lines {
+		if l.key != key || l == keep {
+			lines = append(lines, l)
+		}
 	}
-	defer file.Close()
+	e.lines = lines
+}
 
-	writer := bufio.NewWriter(file)
+func (e *EnvEditor) AddBlankLine() *EnvEditor {
+	e.pendingBlank = true
+	return e
+}
 
-	for _, key := range e.keys {
-		if key == "" {
-			writer.WriteString("\n")
-			continue
+func (e *EnvEditor) Write(path string) error {
+	var b strings.Builder
+	for i, l := range e.lines {
+		if i > 0 {
+			b.WriteString(e.eol)
 		}
-		writer.WriteString(key + "=" + e.keyValue[key] + "\n")
+		b.WriteString(strings.ReplaceAll(l.String(), "\n", e.eol))
+	}
+	if len(e.lines) > 0 && e.trailingEOL {
+		b.WriteString(e.eol)
 	}
 
-	return writer.Flush()
+	return os.WriteFile(path, []byte(b.String()), 0o644)
 }
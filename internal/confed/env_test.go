@@ -0,0 +1,294 @@
+package confed
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const exampleEnv = `# Application environment: dev or prod
+APP_ENV=dev
+
+# Session storage
+SESSION_DRIVER = memory   
+
+# HTTP port
+PORT=8999
+DSN="umono.db"
+`
+
+func writeEnv(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write env file: %v", err)
+	}
+	return path
+}
+
+func readEnv(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read env file: %v", err)
+	}
+	return string(data)
+}
+
+func TestEnvEditor_RoundTrip(t *testing.T) {
+	inputs := []string{
+		exampleEnv,
+		"PORT=8999",
+		"# only a comment\n",
+		"A=1\r\n# comment\r\n\r\nB=2\r\n",
+		"",
+	}
+
+	for _, input := range inputs {
+		path := writeEnv(t, input)
+
+		e := NewEnvEditor()
+		if err := e.Read(path); err != nil {
+			t.Fatalf("Read() unexpected error: %v", err)
+		}
+		if err := e.Write(path); err != nil {
+			t.Fatalf("Write() unexpected error: %v", err)
+		}
+
+		if got := readEnv(t, path); got != input {
+			t.Errorf("round trip changed file:\ngot:  %q\nwant: %q", got, input)
+		}
+	}
+}
+
+func TestEnvEditor_SetValue(t *testing.T) {
+	path := writeEnv(t, exampleEnv)
+
+	e := NewEnvEditor()
+	if err := e.Read(path); err != nil {
+		t.Fatalf("Read() unexpected error: %v", err)
+	}
+
+	err := e.SetValue("APP_ENV", "prod").
+		SetValue("PORT", "8999").
+		AddBlankLine().
+		SetValue("HASHED_USERNAME", "abc").
+		SetValue("HASHED_PASSWORD", "def").
+		Write(path)
+	if err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+
+	want := `# Application environment: dev or prod
+APP_ENV=prod
+
+# Session storage
+SESSION_DRIVER = memory   
+
+# HTTP port
+PORT=8999
+DSN="umono.db"
+
+HASHED_USERNAME=abc
+HASHED_PASSWORD=def
+`
+	if got := readEnv(t, path); got != want {
+		t.Errorf("SetValue() result:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEnvEditor_BlankLineOnlyBeforeNewKeys(t *testing.T) {
+	path := writeEnv(t, exampleEnv)
+
+	e := NewEnvEditor()
+	if err := e.Read(path); err != nil {
+		t.Fatalf("Read() unexpected error: %v", err)
+	}
+
+	if err := e.AddBlankLine().SetValue("PORT", "9000").AddBlankLine().Write(path); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+
+	want := `# Application environment: dev or prod
+APP_ENV=dev
+
+# Session storage
+SESSION_DRIVER = memory   
+
+# HTTP port
+PORT=9000
+DSN="umono.db"
+`
+	if got := readEnv(t, path); got != want {
+		t.Errorf("result:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEnvEditor_Unset(t *testing.T) {
+	path := writeEnv(t, "A=1\n# keep me\nB=2\nA=3\n")
+
+	e := NewEnvEditor()
+	if err := e.Read(path); err != nil {
+		t.Fatalf("Read() unexpected error: %v", err)
+	}
+
+	if v, _ := e.GetValue("A"); v != "3" {
+		t.Errorf("GetValue(A) = %q, want the last definition %q", v, "3")
+	}
+
+	if err := e.Unset("A").Write(path); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+
+	if got, want := readEnv(t, path), "# keep me\nB=2\n"; got != want {
+		t.Errorf("Unset() result = %q, want %q", got, want)
+	}
+
+	if _, ok := e.GetValue("A"); ok {
+		t.Error("GetValue() found unset key")
+	}
+}
+
+func TestEnvEditor_Keys(t *testing.T) {
+	path := writeEnv(t, exampleEnv+"PORT=1\n")
+
+	e := NewEnvEditor()
+	if err := e.Read(path); err != nil {
+		t.Fatalf("Read() unexpected error: %v", err)
+	}
+
+	want := []string{"APP_ENV", "SESSION_DRIVER", "PORT", "DSN"}
+	if got := e.Keys(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+}
+
+func TestEnvEditor_NewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+
+	e := NewEnvEditor()
+	if err := e.SetValue("A", "1").AddBlankLine().SetValue("B", "2").Write(path); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+
+	if got, want := readEnv(t, path), "A=1\n\nB=2\n"; got != want {
+		t.Errorf("new file = %q, want %q", got, want)
+	}
+}
+
+func TestEnvEditor_Syntax(t *testing.T) {
+	t.Setenv("UMONO_TEST_HOME", "/home/umono")
+
+	content := `export PORT=8999
+HOST = localhost # inline comment
+TITLE="My #1 site"
+LITERAL='price: $5 \n'
+ESCAPED="line1\nline2 \"quoted\" \$HOME"
+MULTI="first
+second"
+MULTI_SINGLE='a
+b'
+URL=http://${HOST}:$PORT/path
+FROM_ENV=${UMONO_TEST_HOME}/data
+DEFAULTED=${UNDEFINED_UMONO_KEY:-fallback}
+HASH=abc#def
+EMPTY=
+`
+	env := NewEnvEditor()
+	if err := env.Read(writeEnv(t, content)); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	tests := map[string]string{
+		"PORT":         "8999",
+		"HOST":         "localhost",
+		"TITLE":        "My #1 site",
+		"LITERAL":      `price: $5 \n`,
+		"ESCAPED":      "line1\nline2 \"quoted\" $HOME",
+		"MULTI":        "first\nsecond",
+		"MULTI_SINGLE": "a\nb",
+		"URL":          "http://localhost:8999/path",
+		"FROM_ENV":     "/home/umono/data",
+		"DEFAULTED":    "fallback",
+		"HASH":         "abc#def",
+		"EMPTY":        "",
+	}
+
+	for key, want := range tests {
+		got, ok := env.GetValue(key)
+		if !ok {
+			t.Errorf("GetValue(%q) not found", key)
+			continue
+		}
+		if got != want {
+			t.Errorf("GetValue(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestEnvEditor_UnterminatedQuote(t *testing.T) {
+	env := NewEnvEditor()
+	if err := env.Read(writeEnv(t, "A=1\nB=\"open\nC=3\n")); err == nil {
+		t.Fatal("Read() expected error for unterminated quote")
+	}
+}
+
+func TestEnvEditor_SetValueQuotes(t *testing.T) {
+	values := []string{
+		"plain",
+		"with space",
+		"has #hash",
+		"it's",
+		"$HOME literal",
+		"multi\nline",
+		`back\slash "and" quotes`,
+		"",
+	}
+
+	for _, value := range values {
+		path := writeEnv(t, "export KEY=old # keep me\n")
+
+		env := NewEnvEditor()
+		if err := env.Read(path); err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		if err := env.SetValue("KEY", value).Write(path); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+
+		written := readEnv(t, path)
+		if !strings.HasPrefix(written, "export KEY=") || !strings.HasSuffix(written, " # keep me\n") {
+			t.Errorf("SetValue(%q) wrote %q, want export prefix and comment kept", value, written)
+		}
+
+		reread := NewEnvEditor()
+		if err := reread.Read(path); err != nil {
+			t.Fatalf("Read() after write error = %v (file %q)", err, written)
+		}
+		if got, _ := reread.GetValue("KEY"); got != value {
+			t.Errorf("round trip of %q = %q (file %q)", value, got, written)
+		}
+	}
+}
+
+func TestQuoteValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"8999", "8999"},
+		{"umono.db", "umono.db"},
+		{"JDJhJDEwJGFi+/c=", "JDJhJDEwJGFi+/c="},
+		{"two words", "'two words'"},
+		{"it's", `"it's"`},
+		{"a\nb", `"a\nb"`},
+	}
+
+	for _, tt := range tests {
+		if got := QuoteValue(tt.value); got != tt.want {
+			t.Errorf("QuoteValue(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/umono-cms/cli/internal/logfile"
+)
+
+type Config struct {
+	Strict      bool      `json:"strict"`
+	TrustedKeys []string  `json:"trusted_keys"`
+	Logs        LogConfig `json:"logs"`
+	// HealthPath is requested to check that a started instance is ready.
+	HealthPath string `json:"health_path"`
+}
+
+// LogConfig controls where detached instances write their output. Zero
+// values fall back to the defaults of the logfile package.
+type LogConfig struct {
+	// Path is relative to the project directory unless absolute.
+	Path       string `json:"path"`
+	MaxSizeMB  int    `json:"max_size_mb"`
+	MaxAgeDays int    `json:"max_age_days"`
+	MaxFiles   int    `json:"max_files"`
+}
+
+// Options resolves the log settings for a project. Negative sizes and ages
+// disable that kind of rotation.
+func (c LogConfig) Options(projectPath string) logfile.Options {
+	opts := logfile.DefaultOptions()
+
+	if c.Path != "" {
+		opts.Path = c.Path
+	}
+	if !filepath.IsAbs(opts.Path) {
+		opts.Path = filepath.Join(projectPath, opts.Path)
+	}
+
+	if c.MaxSizeMB != 0 {
+		opts.MaxSize = max(int64(c.MaxSizeMB), 0) * 1024 * 1024
+	}
+	if c.MaxAgeDays != 0 {
+		opts.MaxAge = time.Duration(max(c.MaxAgeDays, 0)) * 24 * time.Hour
+	}
+	if c.MaxFiles > 0 {
+		opts.MaxFiles = c.MaxFiles
+	}
+
+	return opts
+}
+
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "umono", "config.json"), nil
+}
+
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadFromFile(path)
+}
+
+func LoadFromFile(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,91 @@
+package credentials
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+//go:embed common.txt
+var commonList string
+
+var commonPasswords = func() map[string]bool {
+	m := make(map[string]bool)
+	for _, line := range strings.Split(commonList, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			m[line] = true
+		}
+	}
+	return m
+}()
+
+// leet undoes common character substitutions such as "p@ssw0rd".
+var leet = strings.NewReplacer("@", "a", "4", "a", "3", "e", "1", "i", "!", "i", "0", "o", "$", "s", "5", "s", "7", "t")
+
+// isCommon reports whether password is on the list, or is a listed password
+// with digits or symbols appended ("password2024!") or letters substituted.
+// Most entries are shorter than the default minimum length, so these
+// variations are what the list mostly catches.
+func isCommon(password string) bool {
+	lower := strings.ToLower(password)
+	if commonPasswords[lower] {
+		return true
+	}
+
+	stem := strings.TrimRightFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if stem == "" {
+		return false
+	}
+	return commonPasswords[stem] || commonPasswords[leet.Replace(stem)]
+}
+
+type Policy struct {
+	MinLength int
+	// RejectCommon rejects passwords from the built-in list of common
+	// passwords.
+	RejectCommon bool
+	// RejectUsername rejects a password equal to the username.
+	RejectUsername bool
+}
+
+var (
+	DefaultPolicy = Policy{MinLength: 10, RejectCommon: true, RejectUsername: true}
+	// RelaxedPolicy only requires a non-empty password.
+	RelaxedPolicy = Policy{MinLength: 1}
+)
+
+func ValidateUsername(username string) error {
+	if username == "" {
+		return errors.New("username must not be empty")
+	}
+	if strings.ContainsAny(username, " \t") {
+		return errors.New("username must not contain spaces")
+	}
+	return nil
+}
+
+// Check validates password against the policy. An empty username skips the
+// username comparison.
+func (p Policy) Check(username, password string) error {
+	if password == "" {
+		return errors.New("password must not be empty")
+	}
+
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("password must be at least %d characters", p.MinLength)
+	}
+
+	if p.RejectCommon && isCommon(password) {
+		return errors.New("password is too common")
+	}
+
+	if p.RejectUsername && username != "" && strings.EqualFold(username, password) {
+		return errors.New("password must not be the same as the username")
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package credentials
+
+import "testing"
+
+func TestPolicyCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		username string
+		password string
+		wantErr  bool
+	}{
+		{"strong", DefaultPolicy, "admin", "correct-horse-battery", false},
+		{"empty", DefaultPolicy, "admin", "", true},
+		{"too short", DefaultPolicy, "admin", "s3cr3t!", true},
+		{"common", DefaultPolicy, "admin", "qwertyuiop", true},
+		{"common ignores case", DefaultPolicy, "admin", "QwertyUIOP", true},
+		{"common with digits appended", DefaultPolicy, "admin", "password2024", true},
+		{"common with symbols appended", DefaultPolicy, "admin", "sunshine123!", true},
+		{"common with substitutions", DefaultPolicy, "admin", "P@ssw0rd1234", true},
+		{"common word inside", DefaultPolicy, "admin", "password-manager-42", false},
+		{"same as username", DefaultPolicy, "site-administrator", "Site-Administrator", true},
+		{"unknown username", DefaultPolicy, "", "site-administrator", false},
+		{"relaxed short", RelaxedPolicy, "admin", "pw", false},
+		{"relaxed same as username", RelaxedPolicy, "admin", "admin", false},
+		{"relaxed empty", RelaxedPolicy, "admin", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.username, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateUsername(t *testing.T) {
+	tests := map[string]bool{
+		"admin":     false,
+		"":          true,
+		"two words": true,
+	}
+
+	for username, wantErr := range tests {
+		if err := ValidateUsername(username); (err != nil) != wantErr {
+			t.Errorf("ValidateUsername(%q) error = %v, wantErr %v", username, err, wantErr)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package doctor
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/umono-cms/cli/internal/confed"
+	"github.com/umono-cms/cli/internal/project"
+	"github.com/umono-cms/cli/internal/state"
+)
+
+const (
+	diskWarnBytes = 500 << 20
+	diskFailBytes = 100 << 20
+)
+
+func checkPath(projectPath string) Result {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Result{Status: Warn, Message: fmt.Sprintf("could not determine home directory: %v", err)}
+	}
+
+	binDir := filepath.Join(home, ".local", "bin")
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if filepath.Clean(dir) == binDir {
+			return Result{Status: Pass, Message: "~/.local/bin is on PATH"}
+		}
+	}
+
+	return Result{
+		Status:  Warn,
+		Message: "~/.local/bin is not on PATH",
+		Hint:    `add 'export PATH="$HOME/.local/bin:$PATH"' to your shell configuration file`,
+	}
+}
+
+func checkBinary(projectPath string) Result {
+	path := project.FindBinary(projectPath)
+	if path == "" {
+		return Result{
+			Status:  Fail,
+			Message: "umono executable not found",
+			Hint:    "run this command in an Umono project directory",
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Result{Status: Fail, Message: fmt.Sprintf("cannot access umono executable: %v", err)}
+	}
+
+	name := filepath.Base(path)
+	if info.Mode()&0o111 == 0 {
+		return Result{
+			Status:  Fail,
+			Message: fmt.Sprintf("%s file is not executable", name),
+			Hint:    fmt.Sprintf("run 'chmod +x %s' or 'umono doctor --fix'", name),
+		}
+	}
+
+	return Result{Status: Pass, Message: fmt.Sprintf("umono executable found (%s)", name)}
+}
+
+func fixBinary(projectPath string) error {
+	path := project.FindBinary(projectPath)
+	if path == "" {
+		return os.ErrNotExist
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.Chmod(path, info.Mode()|0o111)
+}
+
+func checkArchitecture(projectPath string) Result {
+	path := project.FindBinary(projectPath)
+	if path == "" {
+		return Result{Status: Fail, Message: "umono executable not found"}
+	}
+
+	goos, goarch, err := binaryPlatform(path)
+	if err != nil {
+		return Result{
+			Status:  Fail,
+			Message: fmt.Sprintf("%s is not a recognized executable: %v", filepath.Base(path), err),
+			Hint:    "reinstall with 'umono upgrade'",
+		}
+	}
+
+	if goos != runtime.GOOS || goarch != runtime.GOARCH {
+		return Result{
+			Status:  Fail,
+			Message: fmt.Sprintf("%s is built for %s/%s, this machine is %s/%s", filepath.Base(path), goos, goarch, runtime.GOOS, runtime.GOARCH),
+			Hint:    "reinstall with 'umono upgrade' to get the binary for this platform",
+		}
+	}
+
+	return Result{Status: Pass, Message: fmt.Sprintf("%s is built for %s/%s", filepath.Base(path), goos, goarch)}
+}
+
+func binaryPlatform(path string) (string, string, error) {
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		goos := elfOS(f.OSABI)
+		switch f.Machine {
+		case elf.EM_X86_64:
+			return goos, "amd64", nil
+		case elf.EM_AARCH64:
+			return goos, "arm64", nil
+		}
+		return goos, strings.ToLower(strings.TrimPrefix(f.Machine.String(), "EM_")), nil
+	}
+
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+		return "darwin", machoArch(f.Cpu), nil
+	}
+
+	if f, err := macho.OpenFat(path); err == nil {
+		defer f.Close()
+		for _, arch := range f.Arches {
+			if machoArch(arch.Cpu) == runtime.GOARCH {
+				return "darwin", runtime.GOARCH, nil
+			}
+		}
+		if len(f.Arches) > 0 {
+			return "darwin", machoArch(f.Arches[0].Cpu), nil
+		}
+	}
+
+	return "", "", fmt.Errorf("unknown executable format")
+}
+
+// elfOS maps the ELF OS/ABI byte to a GOOS. Linux binaries, including Go's,
+// usually leave it at the System V default.
+func elfOS(abi elf.OSABI) string {
+	switch abi {
+	case elf.ELFOSABI_NONE, elf.ELFOSABI_LINUX:
+		return "linux"
+	case elf.ELFOSABI_FREEBSD:
+		return "freebsd"
+	case elf.ELFOSABI_NETBSD:
+		return "netbsd"
+	case elf.ELFOSABI_OPENBSD:
+		return "openbsd"
+	case elf.ELFOSABI_SOLARIS:
+		return "solaris"
+	}
+	return strings.ToLower(strings.TrimPrefix(abi.String(), "ELFOSABI_"))
+}
+
+func machoArch(cpu macho.Cpu) string {
+	switch cpu {
+	case macho.CpuAmd64:
+		return "amd64"
+	case macho.CpuArm64:
+		return "arm64"
+	}
+	return strings.ToLower(cpu.String())
+}
+
+func checkEnvKeys(projectPath string) Result {
+	env := confed.NewEnvEditor()
+	if err := env.Read(filepath.Join(projectPath, ".env")); os.IsNotExist(err) {
+		return Result{
+			Status:  Fail,
+			Message: ".env not found",
+			Hint:    "copy .env.example to .env and fill in the values",
+		}
+	} else if err != nil {
+		return Result{
+			Status:  Fail,
+			Message: fmt.Sprintf(".env cannot be parsed: %v", err),
+			Hint:    "fix the syntax error in .env",
+		}
+	}
+
+	example := confed.NewEnvEditor()
+	if err := example.Read(filepath.Join(projectPath, ".env.example")); err != nil {
+		return Result{Status: Pass, Message: ".env found (no .env.example to compare with)"}
+	}
+
+	missing := missingKeys(env, example)
+	if len(missing) > 0 {
+		return Result{
+			Status:  Warn,
+			Message: fmt.Sprintf(".env is missing %s", strings.Join(missing, ", ")),
+			Hint:    "add them from .env.example or run 'umono doctor --fix'",
+		}
+	}
+
+	return Result{Status: Pass, Message: ".env contains every key from .env.example"}
+}
+
+func fixEnvKeys(projectPath string) error {
+	envPath := filepath.Join(projectPath, ".env")
+
+	env := confed.NewEnvEditor()
+	if err := env.Read(envPath); err != nil {
+		return err
+	}
+
+	example := confed.NewEnvEditor()
+	if err := example.Read(filepath.Join(projectPath, ".env.example")); err != nil {
+		return err
+	}
+
+	for _, key := range missingKeys(env, example) {
+		value, _ := example.GetValue(key)
+		env.SetValue(key, value)
+	}
+
+	return env.Write(envPath)
+}
+
+func missingKeys(env, example *confed.EnvEditor) []string {
+	var missing []string
+	for _, key := range example.Keys() {
+		if _, ok := env.GetValue(key); !ok {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+func checkPort(projectPath string) Result {
+	env := confed.NewEnvEditor()
+	if err := env.Read(filepath.Join(projectPath, ".env")); err != nil {
+		return Result{Status: Warn, Message: "cannot check port without .env"}
+	}
+
+	port, ok := env.GetValue("PORT")
+	if !ok || port == "" {
+		return Result{Status: Fail, Message: "PORT is not set in .env", Hint: "set PORT in .env"}
+	}
+
+	portNum, err := strconv.Atoi(port)
+	if err != nil || portNum < 1 || portNum > 65535 {
+		return Result{Status: Fail, Message: fmt.Sprintf("PORT %q is not a valid port", port), Hint: "set PORT to a number between 1024 and 65535"}
+	}
+
+	if st, err := state.Running(projectPath); err == nil && st != nil {
+		return Result{Status: Pass, Message: fmt.Sprintf("port %s is used by this project's Umono (PID: %d)", port, st.PID)}
+	}
+
+	// Checked before binding, where a privileged port would otherwise be
+	// reported as in use.
+	if portNum < 1024 {
+		return Result{
+			Status:  Warn,
+			Message: fmt.Sprintf("port %s is privileged and requires root privileges", port),
+			Hint:    "set PORT to a number between 1024 and 65535",
+		}
+	}
+
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return Result{
+			Status:  Fail,
+			Message: fmt.Sprintf("port %s is already in use", port),
+			Hint:    "stop the process using it or change PORT in .env",
+		}
+	}
+	listener.Close()
+
+	return Result{Status: Pass, Message: fmt.Sprintf("port %s is free", port)}
+}
+
+func checkState(projectPath string) Result {
+	st, err := state.Load(projectPath)
+	if os.IsNotExist(err) {
+		return Result{Status: Pass, Message: "no running instance recorded"}
+	}
+	if err != nil {
+		return Result{Status: Warn, Message: fmt.Sprintf("invalid state file: %v", err), Hint: "run 'umono doctor --fix'"}
+	}
+
+	if !st.Alive() {
+		return Result{
+			Status:  Warn,
+			Message: fmt.Sprintf("stale state file (process %d is not this project's Umono)", st.PID),
+			Hint:    "run 'umono doctor --fix'",
+		}
+	}
+
+	return Result{Status: Pass, Message: fmt.Sprintf("Umono is running (PID: %d)", st.PID)}
+}
+
+func fixState(projectPath string) error {
+	h, err := state.Open(projectPath, true)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	if st, err := h.Read(); err == nil && st.Alive() {
+		return nil
+	}
+	return h.Remove()
+}
+
+func checkDiskSpace(projectPath string) Result {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(projectPath, &stat); err != nil {
+		return Result{Status: Warn, Message: fmt.Sprintf("could not check disk space: %v", err)}
+	}
+
+	available := uint64(stat.Bavail) * uint64(stat.Bsize)
+	message := fmt.Sprintf("%d MB available", available>>20)
+
+	switch {
+	case available < diskFailBytes:
+		return Result{Status: Fail, Message: message, Hint: "free up disk space; the database and uploads need room to grow"}
+	case available < diskWarnBytes:
+		return Result{Status: Warn, Message: message, Hint: "disk space is running low"}
+	}
+
+	return Result{Status: Pass, Message: message}
+}
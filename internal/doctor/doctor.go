@@ -0,0 +1,86 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+)
+
+type Status string
+
+const (
+	Pass Status = "pass"
+	Warn Status = "warn"
+	Fail Status = "fail"
+)
+
+type Result struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+	Fixed   bool   `json:"fixed,omitempty"`
+}
+
+type Check struct {
+	Name string
+	Run  func(projectPath string) Result
+	// Fix repairs the problem found by Run. It is nil when there is no safe
+	// automatic repair.
+	Fix func(projectPath string) error
+}
+
+func Checks() []Check {
+	return []Check{
+		{Name: "path", Run: checkPath},
+		{Name: "binary", Run: checkBinary, Fix: fixBinary},
+		{Name: "architecture", Run: checkArchitecture},
+		{Name: "env", Run: checkEnvKeys, Fix: fixEnvKeys},
+		{Name: "port", Run: checkPort},
+		{Name: "state", Run: checkState, Fix: fixState},
+		{Name: "disk", Run: checkDiskSpace},
+	}
+}
+
+// Run executes every check. With fix set, checks that did not pass are
+// repaired where possible and run again. A result is only marked fixed when
+// the check passes afterwards.
+func Run(projectPath string, fix bool) []Result {
+	return run(Checks(), projectPath, fix)
+}
+
+func run(checks []Check, projectPath string, fix bool) []Result {
+	var results []Result
+
+	for _, check := range checks {
+		result := check.Run(projectPath)
+		result.Name = check.Name
+
+		if fix && result.Status != Pass && check.Fix != nil {
+			if err := check.Fix(projectPath); err != nil {
+				result.Hint = fmt.Sprintf("automatic fix failed: %v", err)
+			} else {
+				result = check.Run(projectPath)
+				result.Name = check.Name
+				result.Fixed = result.Status == Pass
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func HasFailures(results []Result) bool {
+	for _, result := range results {
+		if result.Status == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
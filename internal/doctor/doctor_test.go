@@ -0,0 +1,186 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, ".env.example"), []byte("PORT=8999\nDSN=umono.db\nAPP_ENV=dev\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("PORT=9000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := checkEnvKeys(dir)
+	if result.Status != Warn {
+		t.Fatalf("checkEnvKeys() status = %s, want %s", result.Status, Warn)
+	}
+	if !strings.Contains(result.Message, "DSN") || !strings.Contains(result.Message, "APP_ENV") {
+		t.Errorf("checkEnvKeys() message = %q, want missing keys listed", result.Message)
+	}
+
+	if err := fixEnvKeys(dir); err != nil {
+		t.Fatalf("fixEnvKeys() unexpected error: %v", err)
+	}
+
+	if result := checkEnvKeys(dir); result.Status != Pass {
+		t.Errorf("checkEnvKeys() after fix = %s (%s), want %s", result.Status, result.Message, Pass)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, ".env"))
+	if !strings.Contains(string(data), "PORT=9000") {
+		t.Errorf("fixEnvKeys() changed existing value: %s", data)
+	}
+}
+
+func TestStalePID(t *testing.T) {
+	dir := t.TempDir()
+
+	if result := checkState(dir); result.Status != Pass {
+		t.Errorf("checkState() without state = %s, want %s", result.Status, Pass)
+	}
+
+	// PIDs are capped well below this on Linux and macOS.
+	if err := os.WriteFile(filepath.Join(dir, ".PID"), []byte("999999999"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if result := checkState(dir); result.Status != Warn {
+		t.Errorf("checkState() with stale .PID = %s, want %s", result.Status, Warn)
+	}
+
+	results := Run(dir, true)
+	for _, result := range results {
+		if result.Name == "state" && (!result.Fixed || result.Status != Pass) {
+			t.Errorf("state result after fix = %+v", result)
+		}
+	}
+
+	if exists(filepath.Join(dir, ".PID")) {
+		t.Error("stale .PID was not removed")
+	}
+}
+
+func TestPort(t *testing.T) {
+	tests := []struct {
+		port string
+		want Status
+	}{
+		{"", Fail},
+		{"abc", Fail},
+		{"0", Fail},
+		{"70000", Fail},
+		{"80", Warn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.port, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("PORT="+tt.port+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if result := checkPort(dir); result.Status != tt.want {
+				t.Errorf("checkPort() for %q = %s (%s), want %s", tt.port, result.Status, result.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestBinary(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "umono")
+
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if result := checkBinary(dir); result.Status != Fail {
+		t.Errorf("checkBinary() for non-executable = %s, want %s", result.Status, Fail)
+	}
+
+	if err := fixBinary(dir); err != nil {
+		t.Fatalf("fixBinary() unexpected error: %v", err)
+	}
+
+	if result := checkBinary(dir); result.Status != Pass {
+		t.Errorf("checkBinary() after fix = %s, want %s", result.Status, Pass)
+	}
+
+	if result := checkArchitecture(dir); result.Status != Fail {
+		t.Errorf("checkArchitecture() for a script = %s, want %s", result.Status, Fail)
+	}
+}
+
+func TestRun_FixedOnlyWhenCheckPasses(t *testing.T) {
+	failing := func(string) Result { return Result{Status: Fail, Message: "still broken"} }
+	checks := []Check{
+		{Name: "ineffective", Run: failing, Fix: func(string) error { return nil }},
+	}
+
+	results := run(checks, t.TempDir(), true)
+	if len(results) != 1 {
+		t.Fatalf("run() returned %d results, want 1", len(results))
+	}
+	if results[0].Fixed || results[0].Status != Fail {
+		t.Errorf("result after an ineffective fix = %+v, want an unfixed failure", results[0])
+	}
+}
+
+func TestBinaryPlatform_ELFOS(t *testing.T) {
+	self, err := os.Executable()
+	if err != nil {
+		t.Skip("cannot locate the test binary")
+	}
+	data, err := os.ReadFile(self)
+	if err != nil || len(data) < 8 || string(data[1:4]) != "ELF" {
+		t.Skip("test binary is not an ELF file")
+	}
+
+	tests := []struct {
+		abi  byte
+		want string
+	}{
+		{0, "linux"},
+		{3, "linux"},
+		{9, "freebsd"},
+	}
+
+	for _, tt := range tests {
+		// EI_OSABI is byte 7 of the ELF header.
+		patched := append([]byte(nil), data...)
+		patched[7] = tt.abi
+		path := filepath.Join(t.TempDir(), "umono")
+		if err := os.WriteFile(path, patched, 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		goos, _, err := binaryPlatform(path)
+		if err != nil {
+			t.Fatalf("binaryPlatform() error = %v", err)
+		}
+		if goos != tt.want {
+			t.Errorf("binaryPlatform() with OS/ABI %d = %s, want %s", tt.abi, goos, tt.want)
+		}
+	}
+}
+
+func TestBinary_PlatformSuffix(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "umono-linux-amd64"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if result := checkBinary(dir); result.Status != Pass {
+		t.Errorf("checkBinary() for a suffixed binary = %s (%s), want %s", result.Status, result.Message, Pass)
+	}
+	if result := checkArchitecture(dir); result.Status != Fail || !strings.Contains(result.Message, "umono-linux-amd64") {
+		t.Errorf("checkArchitecture() for a suffixed script = %+v, want a failure naming it", result)
+	}
+}
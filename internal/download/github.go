@@ -2,6 +2,7 @@ package download
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"context"
 	"fmt"
@@ -14,6 +15,8 @@ import (
 
 	"github.com/google/go-github/v68/github"
 	"github.com/umono-cms/cli/internal/checksum"
+	"github.com/umono-cms/cli/internal/signature"
+	"github.com/umono-cms/cli/internal/trust"
 )
 
 const (
@@ -21,16 +24,43 @@ const (
 	repo  = "umono"
 )
 
+const (
+	checksumsAssetName = "checksums.txt"
+	signatureAssetName = "checksums.txt.minisig"
+)
+
 type Client struct {
-	gh       *github.Client
-	verifier *checksum.Verifier
+	gh          *github.Client
+	verifier    *checksum.Verifier
+	trustedKeys []*signature.PublicKey
+	pins        *trust.Store
 }
 
-func NewClient() *Client {
-	return &Client{
+// NewClient trusts the release key built into the CLI, if any. A malformed
+// built-in key is an error rather than silently disabling signature checks.
+func NewClient() (*Client, error) {
+	client := &Client{
 		gh:       github.NewClient(nil),
 		verifier: checksum.NewVerifier(),
 	}
+
+	if signature.ReleaseKey != "" {
+		key, err := signature.ParsePublicKey(signature.ReleaseKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid built-in release key: %w", err)
+		}
+		client.trustedKeys = append(client.trustedKeys, key)
+	}
+
+	return client, nil
+}
+
+func (c *Client) AddTrustedKey(key *signature.PublicKey) {
+	c.trustedKeys = append(c.trustedKeys, key)
+}
+
+func (c *Client) SetPinStore(store *trust.Store) {
+	c.pins = store
 }
 
 type ReleaseInfo struct {
@@ -40,6 +70,8 @@ type ReleaseInfo struct {
 	AssetSize    int64
 	ChecksumURL  string
 	HasChecksums bool
+	SignatureURL string
+	HasSignature bool
 }
 
 func (c *Client) GetLatestRelease() (*ReleaseInfo, error) {
@@ -64,6 +96,30 @@ func (c *Client) GetReleaseByTag(tag string) (*ReleaseInfo, error) {
 	return c.findAssetForPlatform(release)
 }
 
+func (c *Client) ListReleaseTags() ([]string, error) {
+	ctx := context.Background()
+
+	var tags []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		releases, resp, err := c.gh.Repositories.ListReleases(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("could not list releases: %w", err)
+		}
+
+		for _, release := range releases {
+			if !release.GetDraft() {
+				tags = append(tags, release.GetTagName())
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return tags, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
 func (c *Client) findAssetForPlatform(release *github.RepositoryRelease) (*ReleaseInfo, error) {
 	osName := runtime.GOOS
 	arch := runtime.GOARCH
@@ -75,10 +131,13 @@ func (c *Client) findAssetForPlatform(release *github.RepositoryRelease) (*Relea
 	}
 
 	for _, asset := range release.Assets {
-		if asset.GetName() == "checksums.txt" {
+		switch asset.GetName() {
+		case checksumsAssetName:
 			info.ChecksumURL = asset.GetBrowserDownloadURL()
 			info.HasChecksums = true
-			break
+		case signatureAssetName:
+			info.SignatureURL = asset.GetBrowserDownloadURL()
+			info.HasSignature = true
 		}
 	}
 
@@ -96,91 +155,247 @@ func (c *Client) findAssetForPlatform(release *github.RepositoryRelease) (*Relea
 	return nil, fmt.Errorf("no asset found for platform: %s (%s)", platformName, release.GetTagName())
 }
 
-func (c *Client) DownloadAndExtract(info *ReleaseInfo, destDir string) error {
+type Archive struct {
+	Version   string
+	AssetName string
+	// Hash is computed with the algorithm used by the release's
+	// checksums.txt; SHA256 is always SHA-256.
+	Hash   string
+	SHA256 string
+	// Files maps the slash-separated path of every regular file in the
+	// archive to its SHA-256.
+	Files map[string]string
+}
+
+func (c *Client) DownloadAndExtract(info *ReleaseInfo, destDir string) (*Archive, error) {
+	return c.downloadAndExtract(info, destDir, false)
+}
+
+func (c *Client) DownloadAndExtractWithStrictVerification(info *ReleaseInfo, destDir string) (*Archive, error) {
+	if !info.HasChecksums || info.ChecksumURL == "" {
+		return nil, fmt.Errorf("strict verification enabled but no checksums available for release %s", info.Version)
+	}
+	return c.downloadAndExtract(info, destDir, true)
+}
+
+func (c *Client) LoadChecksums(info *ReleaseInfo) (*checksum.Verifier, error) {
+	if err := c.loadChecksums(info, false); err != nil {
+		return nil, err
+	}
+	return c.verifier, nil
+}
+
+func (c *Client) loadChecksums(info *ReleaseInfo, strict bool) error {
+	if !info.HasChecksums || info.ChecksumURL == "" {
+		return fmt.Errorf("no checksums available for release %s", info.Version)
+	}
+
+	checksums, err := fetch(info.ChecksumURL)
+	if err != nil {
+		return fmt.Errorf("failed to load checksums: %w", err)
+	}
+
+	if err := c.verifyChecksumsSignature(info, checksums, strict); err != nil {
+		return err
+	}
+
+	if err := c.verifier.Load(bytes.NewReader(checksums)); err != nil {
+		return fmt.Errorf("failed to load checksums: %w", err)
+	}
+
+	if !c.verifier.HasChecksum(info.AssetName) {
+		return fmt.Errorf("no checksum found for %s in checksums.txt", info.AssetName)
+	}
+
+	return nil
+}
+
+func (c *Client) downloadAndExtract(info *ReleaseInfo, destDir string, strict bool) (*Archive, error) {
 	if info.HasChecksums && info.ChecksumURL != "" {
 		fmt.Println("🔐 Verifying checksums...")
-		if err := c.verifier.LoadFromURL(info.ChecksumURL); err != nil {
-			return fmt.Errorf("failed to load checksums: %w", err)
+		if err := c.loadChecksums(info, strict); err != nil {
+			return nil, err
 		}
+	}
 
-		if !c.verifier.HasChecksum(info.AssetName) {
-			return fmt.Errorf("no checksum found for %s in checksums.txt", info.AssetName)
-		}
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
-	tmpFile, err := os.CreateTemp("", "umono-*.tar.gz")
+	// Files are extracted next to their final location and only moved into
+	// place once the archive hash is known to be good.
+	stagingDir, err := os.MkdirTemp(destDir, ".umono-staging-*")
 	if err != nil {
-		return fmt.Errorf("failed to create temporary file: %w", err)
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
 	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
+	defer os.RemoveAll(stagingDir)
 
 	fmt.Printf("📦 Downloading %s (%s)...\n", info.AssetName, info.Version)
-	if err := downloadFile(info.AssetURL, tmpFile); err != nil {
-		return fmt.Errorf("download failed: %w", err)
+	body, err := openDownload(info.AssetURL)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	defer body.Close()
+
+	sha := checksum.NewHashingReader(body)
+	alg, _ := c.verifier.Algorithm(info.AssetName)
+	hr := checksum.NewHashingReaderWithAlgorithm(sha, alg)
+	files, err := extractTarGz(hr, stagingDir)
+	if err != nil {
+		return nil, fmt.Errorf("extraction failed: %w", err)
+	}
+
+	// The tar reader stops at the end-of-archive marker, so read whatever
+	// trails it to make sure the hash covers the whole download.
+	if _, err := io.Copy(io.Discard, hr); err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
 	}
 
 	if info.HasChecksums {
 		fmt.Printf("🔍 Verifying %s...\n", info.AssetName)
-		if err := c.verifier.VerifyFile(tmpFile.Name(), info.AssetName); err != nil {
+		if err := c.verifier.VerifyHash(info.AssetName, hr.Sum()); err != nil {
 			if mismatchErr, ok := err.(*checksum.ChecksumMismatchError); ok {
-				return fmt.Errorf("❌ SECURITY WARNING: Checksum verification failed!\n"+
+				return nil, fmt.Errorf("❌ SECURITY WARNING: Checksum verification failed!\n"+
 					"   File: %s\n"+
 					"   Expected: %s\n"+
 					"   Got:      %s\n"+
 					"   The downloaded file may be corrupted or tampered with.",
 					mismatchErr.Filename, mismatchErr.Expected, mismatchErr.Actual)
 			}
-			return fmt.Errorf("checksum verification failed: %w", err)
+			return nil, fmt.Errorf("checksum verification failed: %w", err)
 		}
 		fmt.Println("✅ Checksum verified")
 	} else {
 		fmt.Println("⚠️  Warning: No checksums available for this release")
 	}
 
-	if _, err := tmpFile.Seek(0, 0); err != nil {
-		return fmt.Errorf("failed to reset file pointer: %w", err)
+	if err := c.checkPin(info, sha.Sum(), strict); err != nil {
+		return nil, err
 	}
 
 	fmt.Printf("📂 Extracting to %s...\n", destDir)
-	if err := extractTarGz(tmpFile, destDir); err != nil {
-		return fmt.Errorf("extraction failed: %w", err)
+	if err := commitStaging(stagingDir, destDir); err != nil {
+		return nil, fmt.Errorf("extraction failed: %w", err)
+	}
+
+	c.recordPin(info, sha.Sum())
+
+	fmt.Print("✅ Download completed successfully!\n\n")
+	return &Archive{
+		Version:   info.Version,
+		AssetName: info.AssetName,
+		Hash:      hr.Sum(),
+		SHA256:    sha.Sum(),
+		Files:     files,
+	}, nil
+}
+
+func (c *Client) checkPin(info *ReleaseInfo, hash string, strict bool) error {
+	if c.pins == nil {
+		return nil
+	}
+
+	err := c.pins.Check(info.Version, info.AssetName, hash)
+	mismatchErr, ok := err.(*trust.MismatchError)
+	if !ok {
+		return err
 	}
 
-	fmt.Println("✅ Download completed successfully!\n")
+	if strict {
+		return fmt.Errorf("❌ SECURITY WARNING: Release %s was re-published with different assets!\n"+
+			"   File:   %s\n"+
+			"   Pinned: %s\n"+
+			"   Got:    %s\n"+
+			"   Refusing to install a changed release in strict mode.",
+			mismatchErr.Tag, mismatchErr.Asset, mismatchErr.Pinned, mismatchErr.Received)
+	}
+
+	fmt.Printf("⚠️  WARNING: Release %s was re-published with different assets!\n"+
+		"   File:   %s\n"+
+		"   Pinned: %s\n"+
+		"   Got:    %s\n"+
+		"   The release may have been tampered with. Use --strict to refuse changed releases.\n",
+		mismatchErr.Tag, mismatchErr.Asset, mismatchErr.Pinned, mismatchErr.Received)
 	return nil
 }
 
-func (c *Client) DownloadAndExtractWithStrictVerification(info *ReleaseInfo, destDir string) error {
-	if !info.HasChecksums || info.ChecksumURL == "" {
-		return fmt.Errorf("strict verification enabled but no checksums available for release %s", info.Version)
+func (c *Client) recordPin(info *ReleaseInfo, hash string) {
+	if c.pins == nil {
+		return
+	}
+
+	c.pins.Record(info.Version, info.AssetName, hash)
+	if err := c.pins.Save(); err != nil {
+		fmt.Printf("⚠️  Warning: failed to record release checksum: %v\n", err)
 	}
-	return c.DownloadAndExtract(info, destDir)
 }
 
-func downloadFile(url string, dest io.Writer) error {
+func (c *Client) verifyChecksumsSignature(info *ReleaseInfo, checksums []byte, strict bool) error {
+	if !info.HasSignature || info.SignatureURL == "" {
+		if strict {
+			return fmt.Errorf("strict verification enabled but %s is not signed for release %s", checksumsAssetName, info.Version)
+		}
+		fmt.Printf("⚠️  Warning: %s is not signed for this release\n", checksumsAssetName)
+		return nil
+	}
+
+	if len(c.trustedKeys) == 0 {
+		if strict {
+			return fmt.Errorf("strict verification enabled but no trusted signing keys are configured")
+		}
+		fmt.Println("⚠️  Warning: No trusted signing keys configured, skipping signature verification")
+		return nil
+	}
+
+	sig, err := fetch(info.SignatureURL)
+	if err != nil {
+		return fmt.Errorf("failed to load signature: %w", err)
+	}
+
+	// A signature by an unknown key is refused even when not strict: unlike
+	// a missing signature, it is what a substituted release would carry.
+	key, err := signature.Verify(checksums, sig, c.trustedKeys)
+	if err != nil {
+		return fmt.Errorf("❌ SECURITY WARNING: Signature verification of %s failed: %w", checksumsAssetName, err)
+	}
+
+	fmt.Printf("✅ Signature verified (key %s)\n", key.ID())
+	return nil
+}
+
+func fetch(url string) ([]byte, error) {
+	body, err := openDownload(url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return io.ReadAll(body)
+}
+
+func openDownload(url string) (io.ReadCloser, error) {
 	resp, err := http.Get(url)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP error: %s", resp.Status)
+		resp.Body.Close()
+		return nil, fmt.Errorf("HTTP error: %s", resp.Status)
 	}
 
-	_, err = io.Copy(dest, resp.Body)
-	return err
+	return resp.Body, nil
 }
 
-func extractTarGz(src io.Reader, destDir string) error {
+func extractTarGz(src io.Reader, destDir string) (map[string]string, error) {
 	gzr, err := gzip.NewReader(src)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer gzr.Close()
 
 	tr := tar.NewReader(gzr)
+	files := make(map[string]string)
 
 	for {
 		header, err := tr.Next()
@@ -188,41 +403,63 @@ func extractTarGz(src io.Reader, destDir string) error {
 			break
 		}
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		cleanName := filepath.Clean(header.Name)
 		if strings.HasPrefix(cleanName, "..") || strings.HasPrefix(cleanName, "/") {
-			return fmt.Errorf("invalid file path in archive: %s", header.Name)
+			return nil, fmt.Errorf("invalid file path in archive: %s", header.Name)
 		}
 
 		target := filepath.Join(destDir, cleanName)
 
 		if !strings.HasPrefix(filepath.Clean(target), filepath.Clean(destDir)) {
-			return fmt.Errorf("invalid file path in archive: %s", header.Name)
+			return nil, fmt.Errorf("invalid file path in archive: %s", header.Name)
 		}
 
 		switch header.Typeflag {
 		case tar.TypeDir:
 			if err := os.MkdirAll(target, 0o755); err != nil {
-				return err
+				return nil, err
 			}
 
 		case tar.TypeReg:
 			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
-				return err
+				return nil, err
 			}
 
 			outFile, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(header.Mode))
 			if err != nil {
-				return err
+				return nil, err
 			}
 
-			if _, err := io.Copy(outFile, tr); err != nil {
+			hr := checksum.NewHashingReader(tr)
+			if _, err := io.Copy(outFile, hr); err != nil {
 				outFile.Close()
-				return err
+				return nil, err
 			}
 			outFile.Close()
+
+			files[filepath.ToSlash(cleanName)] = hr.Sum()
+		}
+	}
+
+	return files, nil
+}
+
+func commitStaging(stagingDir, destDir string) error {
+	entries, err := os.ReadDir(stagingDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		target := filepath.Join(destDir, entry.Name())
+		if err := os.RemoveAll(target); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(stagingDir, entry.Name()), target); err != nil {
+			return err
 		}
 	}
 
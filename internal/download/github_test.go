@@ -0,0 +1,240 @@
+package download
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/umono-cms/cli/internal/signature"
+	"github.com/umono-cms/cli/internal/trust"
+)
+
+func buildArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+
+	for name, content := range files {
+		header := &tar.Header{
+			Name:     name,
+			Mode:     0o755,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("failed to write tar header: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write tar content: %v", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar writer: %v", err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatalf("failed to close gzip writer: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func serveRelease(t *testing.T, archive []byte, checksum string) *ReleaseInfo {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/umono.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+	mux.HandleFunc("/checksums.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  umono.tar.gz\n", checksum)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return &ReleaseInfo{
+		Version:      "v1.0.0",
+		AssetName:    "umono.tar.gz",
+		AssetURL:     server.URL + "/umono.tar.gz",
+		ChecksumURL:  server.URL + "/checksums.txt",
+		HasChecksums: true,
+	}
+}
+
+func TestDownloadAndExtract(t *testing.T) {
+	archive := buildArchive(t, map[string]string{
+		"umono":        "binary",
+		".env.example": "PORT=8999\n",
+	})
+	sum := sha256.Sum256(archive)
+
+	info := serveRelease(t, archive, hex.EncodeToString(sum[:]))
+	destDir := t.TempDir()
+
+	result, err := newTestClient(t).DownloadAndExtract(info, destDir)
+	if err != nil {
+		t.Fatalf("DownloadAndExtract() unexpected error: %v", err)
+	}
+
+	if result.Hash != hex.EncodeToString(sum[:]) {
+		t.Errorf("archive hash = %s, want %s", result.Hash, hex.EncodeToString(sum[:]))
+	}
+
+	binarySum := sha256.Sum256([]byte("binary"))
+	if result.Files["umono"] != hex.EncodeToString(binarySum[:]) {
+		t.Errorf("recorded hash for umono = %s, want %s", result.Files["umono"], hex.EncodeToString(binarySum[:]))
+	}
+
+	data, err := os.ReadFile(filepath.Join(destDir, "umono"))
+	if err != nil {
+		t.Fatalf("extracted binary not found: %v", err)
+	}
+	if string(data) != "binary" {
+		t.Errorf("extracted binary = %q, want %q", data, "binary")
+	}
+
+	entries, _ := os.ReadDir(destDir)
+	if len(entries) != 2 {
+		t.Errorf("destination has %d entries, want 2 (staging directory left behind?)", len(entries))
+	}
+}
+
+func TestDownloadAndExtract_ChecksumMismatch(t *testing.T) {
+	archive := buildArchive(t, map[string]string{"umono": "binary"})
+
+	info := serveRelease(t, archive, "0000000000000000000000000000000000000000000000000000000000000000")
+	destDir := t.TempDir()
+
+	if _, err := newTestClient(t).DownloadAndExtract(info, destDir); err == nil {
+		t.Fatal("DownloadAndExtract() expected error for mismatched checksum")
+	}
+
+	entries, _ := os.ReadDir(destDir)
+	if len(entries) != 0 {
+		t.Errorf("destination has %d entries after failed verification, want 0", len(entries))
+	}
+}
+
+func TestDownloadAndExtractWithStrictVerification_Unsigned(t *testing.T) {
+	archive := buildArchive(t, map[string]string{"umono": "binary"})
+	sum := sha256.Sum256(archive)
+
+	info := serveRelease(t, archive, hex.EncodeToString(sum[:]))
+	destDir := t.TempDir()
+
+	if _, err := newTestClient(t).DownloadAndExtractWithStrictVerification(info, destDir); err == nil {
+		t.Fatal("DownloadAndExtractWithStrictVerification() expected error for unsigned checksums")
+	}
+
+	entries, _ := os.ReadDir(destDir)
+	if len(entries) != 0 {
+		t.Errorf("destination has %d entries after refused download, want 0", len(entries))
+	}
+}
+
+func TestDownloadAndExtract_UnknownSigningKey(t *testing.T) {
+	newKey := func() (string, ed25519.PrivateKey, []byte) {
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		id := make([]byte, 8)
+		rand.Read(id)
+		raw := append(append([]byte("Ed"), id...), public...)
+		return "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(raw) + "\n", private, id
+	}
+
+	archive := buildArchive(t, map[string]string{"umono": "binary"})
+	sum := sha256.Sum256(archive)
+	info := serveRelease(t, archive, hex.EncodeToString(sum[:]))
+	checksums := []byte(fmt.Sprintf("%s  umono.tar.gz\n", hex.EncodeToString(sum[:])))
+
+	// The checksums are signed, but by a key the client does not trust.
+	_, private, id := newKey()
+	sig := ed25519.Sign(private, checksums)
+	global := ed25519.Sign(private, append(append([]byte{}, sig...), "release"...))
+	sigFile := fmt.Sprintf("untrusted comment: signature\n%s\ntrusted comment: release\n%s\n",
+		base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), id...), sig...)),
+		base64.StdEncoding.EncodeToString(global))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, sigFile)
+	}))
+	t.Cleanup(server.Close)
+	info.SignatureURL = server.URL + "/checksums.txt.minisig"
+	info.HasSignature = true
+
+	trusted, _, _ := newKey()
+	key, err := signature.ParsePublicKey(trusted)
+	if err != nil {
+		t.Fatalf("ParsePublicKey() error = %v", err)
+	}
+	client := newTestClient(t)
+	client.AddTrustedKey(key)
+
+	if _, err := client.DownloadAndExtract(info, t.TempDir()); err == nil {
+		t.Fatal("DownloadAndExtract() accepted checksums signed with an unknown key")
+	}
+}
+
+func TestDownloadAndExtract_PinnedChecksumChanged(t *testing.T) {
+	archive := buildArchive(t, map[string]string{"umono": "republished"})
+	sum := sha256.Sum256(archive)
+
+	info := serveRelease(t, archive, hex.EncodeToString(sum[:]))
+
+	pins, err := trust.Load(filepath.Join(t.TempDir(), "known_releases.json"))
+	if err != nil {
+		t.Fatalf("trust.Load() unexpected error: %v", err)
+	}
+	pinned := "1111111111111111111111111111111111111111111111111111111111111111"
+	pins.Record(info.Version, info.AssetName, pinned)
+
+	client := newTestClient(t)
+	client.SetPinStore(pins)
+
+	if _, err := client.DownloadAndExtract(info, t.TempDir()); err != nil {
+		t.Fatalf("DownloadAndExtract() unexpected error: %v", err)
+	}
+
+	if got := pins.Releases[info.Version][info.AssetName]; got != pinned {
+		t.Errorf("pinned checksum = %s, want %s to be kept", got, pinned)
+	}
+
+	if err := client.checkPin(info, hex.EncodeToString(sum[:]), true); err == nil {
+		t.Error("checkPin() expected error in strict mode")
+	}
+}
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return client
+}
+
+func TestNewClient_InvalidReleaseKey(t *testing.T) {
+	original := signature.ReleaseKey
+	defer func() { signature.ReleaseKey = original }()
+
+	signature.ReleaseKey = "not a key"
+	if _, err := NewClient(); err == nil {
+		t.Error("NewClient() with a malformed built-in key returned no error")
+	}
+}
@@ -4,13 +4,51 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/google/go-github/v68/github"
 )
 
+// Manifest is the umono.json published with a release. Unknown fields are
+// ignored so older CLIs can still read newer manifests.
 type Manifest struct {
 	MinCLIVersion string `json:"min_cli_version"`
+	MaxCLIVersion string `json:"max_cli_version,omitempty"`
+
+	// Platforms lists supported "os/arch" pairs, e.g. "linux/amd64". An
+	// entry without an architecture matches every architecture of that OS.
+	// An empty list means every platform is supported.
+	Platforms []string `json:"platforms,omitempty"`
+
+	RequiredEnv   []string `json:"required_env,omitempty"`
+	DeprecatedEnv []string `json:"deprecated_env,omitempty"`
+
+	Migrations []Migration `json:"migrations,omitempty"`
+
+	// Missing is set when the release does not publish a manifest and the
+	// defaults are used instead.
+	Missing bool `json:"-"`
+}
+
+// Migration is a data migration step the release performs on start. It
+// moves the data to SchemaVersion.
+type Migration struct {
+	ID            string `json:"id"`
+	Description   string `json:"description,omitempty"`
+	SchemaVersion int    `json:"schema_version"`
+}
+
+// SchemaVersion is the data schema version the release migrates to, 0 when
+// it declares no migrations.
+func (m *Manifest) SchemaVersion() int {
+	latest := 0
+	for _, migration := range m.Migrations {
+		if migration.SchemaVersion > latest {
+			latest = migration.SchemaVersion
+		}
+	}
+	return latest
 }
 
 func (c *Client) GetManifest() (*Manifest, error) {
@@ -45,7 +83,7 @@ func (c *Client) fetchManifestFromRelease(release *github.RepositoryRelease) (*M
 	}
 
 	if manifestURL == "" {
-		return &Manifest{MinCLIVersion: "0.0.0"}, nil
+		return &Manifest{MinCLIVersion: "0.0.0", Missing: true}, nil
 	}
 
 	resp, err := http.Get(manifestURL)
@@ -58,10 +96,18 @@ func (c *Client) fetchManifestFromRelease(release *github.RepositoryRelease) (*M
 		return nil, fmt.Errorf("failed to fetch manifest: HTTP %d", resp.StatusCode)
 	}
 
+	return parseManifest(resp.Body)
+}
+
+func parseManifest(r io.Reader) (*Manifest, error) {
 	var manifest Manifest
-	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
 		return nil, fmt.Errorf("failed to parse manifest: %w", err)
 	}
 
+	if manifest.MinCLIVersion == "" {
+		manifest.MinCLIVersion = "0.0.0"
+	}
+
 	return &manifest, nil
 }
@@ -0,0 +1,60 @@
+package download
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	input := `{
+  "min_cli_version": "0.2.0",
+  "max_cli_version": "1.0.0",
+  "platforms": ["linux/amd64", "darwin"],
+  "required_env": ["PORT", "DSN"],
+  "deprecated_env": ["SESSION_DRIVER"],
+  "migrations": [
+    {"id": "add-pages", "description": "Add pages table", "schema_version": 2},
+    {"id": "init", "schema_version": 1, "checksum": "ignored"}
+  ],
+  "some_future_field": {"nested": true}
+}`
+
+	manifest, err := parseManifest(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseManifest() unexpected error: %v", err)
+	}
+
+	if manifest.MinCLIVersion != "0.2.0" || manifest.MaxCLIVersion != "1.0.0" {
+		t.Errorf("CLI versions = %q..%q", manifest.MinCLIVersion, manifest.MaxCLIVersion)
+	}
+	if want := []string{"linux/amd64", "darwin"}; !reflect.DeepEqual(manifest.Platforms, want) {
+		t.Errorf("Platforms = %v, want %v", manifest.Platforms, want)
+	}
+	if want := []string{"PORT", "DSN"}; !reflect.DeepEqual(manifest.RequiredEnv, want) {
+		t.Errorf("RequiredEnv = %v, want %v", manifest.RequiredEnv, want)
+	}
+	if want := []string{"SESSION_DRIVER"}; !reflect.DeepEqual(manifest.DeprecatedEnv, want) {
+		t.Errorf("DeprecatedEnv = %v, want %v", manifest.DeprecatedEnv, want)
+	}
+	if len(manifest.Migrations) != 2 {
+		t.Fatalf("Migrations has %d entries, want 2", len(manifest.Migrations))
+	}
+	if got := manifest.SchemaVersion(); got != 2 {
+		t.Errorf("SchemaVersion() = %d, want 2", got)
+	}
+}
+
+func TestParseManifest_Minimal(t *testing.T) {
+	manifest, err := parseManifest(strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("parseManifest() unexpected error: %v", err)
+	}
+
+	if manifest.MinCLIVersion != "0.0.0" {
+		t.Errorf("MinCLIVersion = %q, want %q", manifest.MinCLIVersion, "0.0.0")
+	}
+	if manifest.SchemaVersion() != 0 {
+		t.Errorf("SchemaVersion() = %d, want 0", manifest.SchemaVersion())
+	}
+}
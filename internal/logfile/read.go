@@ -0,0 +1,384 @@
+package logfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Files returns the existing log files for path, oldest first, ending with
+// path itself.
+func Files(path string) []string {
+	var files []string
+
+	var backups []int
+	matches, _ := globBackups(path)
+	for n := range matches {
+		backups = append(backups, n)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(backups)))
+	for _, n := range backups {
+		files = append(files, matches[n])
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	}
+
+	return files
+}
+
+func globBackups(path string) (map[int]string, error) {
+	names, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make(map[int]string)
+	for _, name := range names {
+		if n, err := strconv.Atoi(strings.TrimPrefix(name, path+".")); err == nil && n > 0 {
+			backups[n] = name
+		}
+	}
+	return backups, nil
+}
+
+// Line is one line of server output with whatever structure could be
+// recognised in it.
+type Line struct {
+	Text string
+	// Time is zero when the line carries no recognisable timestamp.
+	Time time.Time
+	// Fields is set for JSON log lines.
+	Fields map[string]any
+}
+
+var textTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006/01/02 15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+var textTimePattern = regexp.MustCompile(`^\[?(\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)`)
+
+func ParseLine(text string) Line {
+	line := Line{Text: text}
+
+	trimmed := strings.TrimSpace(text)
+	if strings.HasPrefix(trimmed, "{") {
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(trimmed), &fields); err == nil {
+			line.Fields = fields
+			line.Time = jsonTime(fields)
+			return line
+		}
+	}
+
+	if m := textTimePattern.FindStringSubmatch(trimmed); m != nil {
+		for _, layout := range textTimeLayouts {
+			if t, err := time.ParseInLocation(layout, m[1], time.Local); err == nil {
+				line.Time = t
+				break
+			}
+		}
+	}
+
+	return line
+}
+
+func jsonTime(fields map[string]any) time.Time {
+	for _, key := range []string{"time", "ts", "timestamp", "@timestamp"} {
+		switch v := fields[key].(type) {
+		case string:
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t
+			}
+		case float64:
+			sec := int64(v)
+			return time.Unix(sec, int64((v-float64(sec))*1e9))
+		}
+	}
+	return time.Time{}
+}
+
+func (l Line) Level() string {
+	for _, key := range []string{"level", "lvl", "severity"} {
+		if v, ok := l.Fields[key].(string); ok {
+			return strings.ToUpper(v)
+		}
+	}
+	return ""
+}
+
+func (l Line) Message() string {
+	for _, key := range []string{"msg", "message"} {
+		if v, ok := l.Fields[key].(string); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// Filter selects lines by time and pattern. Lines without a timestamp, such
+// as stack traces, take the time of the closest earlier line.
+type Filter struct {
+	Since   time.Time
+	Pattern *regexp.Regexp
+	last    time.Time
+}
+
+func (f *Filter) Match(line Line) bool {
+	if !line.Time.IsZero() {
+		f.last = line.Time
+	}
+
+	if !f.Since.IsZero() && (f.last.IsZero() || f.last.Before(f.Since)) {
+		return false
+	}
+
+	if f.Pattern != nil && !f.Pattern.MatchString(line.Text) {
+		return false
+	}
+
+	return true
+}
+
+// ParseSince accepts a duration relative to now ("10m", "2h") or an absolute
+// time ("2024-05-01", RFC 3339).
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q: use a duration like 10m or a date like 2006-01-02", value)
+}
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorGreen  = "\033[32m"
+	colorGray   = "\033[90m"
+)
+
+// Format renders a line for the terminal. JSON lines are shown as
+// "time LEVEL message key=value ..."; other lines are returned unchanged.
+func Format(line Line, color bool) string {
+	if line.Fields == nil {
+		return line.Text
+	}
+
+	var b strings.Builder
+
+	if !line.Time.IsZero() {
+		b.WriteString(line.Time.Local().Format("2006-01-02 15:04:05"))
+		b.WriteByte(' ')
+	}
+
+	if level := line.Level(); level != "" {
+		padded := fmt.Sprintf("%-5s", level)
+		if c := levelColor(level); color && c != "" {
+			padded = c + padded + colorReset
+		}
+		b.WriteString(padded)
+		b.WriteByte(' ')
+	}
+
+	b.WriteString(line.Message())
+
+	skip := map[string]bool{"time": true, "ts": true, "timestamp": true, "@timestamp": true, "level": true, "lvl": true, "severity": true, "msg": true, "message": true}
+	keys := make([]string, 0, len(line.Fields))
+	for key := range line.Fields {
+		if !skip[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, _ := json.Marshal(line.Fields[key])
+		if s, ok := line.Fields[key].(string); ok && !strings.ContainsAny(s, " \t\"=") {
+			value = []byte(s)
+		}
+		if color {
+			fmt.Fprintf(&b, " %s%s=%s%s", colorGray, key, colorReset, value)
+		} else {
+			fmt.Fprintf(&b, " %s=%s", key, value)
+		}
+	}
+
+	return b.String()
+}
+
+func levelColor(level string) string {
+	switch level {
+	case "ERROR", "ERR", "FATAL", "PANIC", "CRITICAL":
+		return colorRed
+	case "WARN", "WARNING":
+		return colorYellow
+	case "INFO":
+		return colorGreen
+	case "DEBUG", "TRACE":
+		return colorGray
+	}
+	return ""
+}
+
+// Follower reads a log file as it grows and continues with the new file
+// when the log is rotated or truncated.
+type Follower struct {
+	path    string
+	file    *os.File
+	info    os.FileInfo
+	partial []byte
+}
+
+// NewFollower starts reading path from its beginning. A missing file is
+// read once it appears, e.g. after logrotate moved the old one away.
+func NewFollower(path string) (*Follower, error) {
+	f := &Follower{path: path}
+	if err := f.reopen(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *Follower) reopen() error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file = file
+	f.info = info
+	return nil
+}
+
+// Poll returns the complete lines written since the last call.
+func (f *Follower) Poll() ([]string, error) {
+	if f.file == nil {
+		if err := f.reopen(); err != nil {
+			return nil, nil
+		}
+	}
+
+	lines, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		// Between the rename and the creation of the new file.
+		return lines, nil
+	}
+
+	if !os.SameFile(info, f.info) {
+		// Rotated: finish the old file before moving on.
+		rest, err := f.read()
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, rest...)
+		lines = append(lines, f.Flush()...)
+
+		if err := f.reopen(); err != nil {
+			return lines, nil
+		}
+		more, err := f.read()
+		if err != nil {
+			return nil, err
+		}
+		return append(lines, more...), nil
+	}
+
+	if offset, err := f.file.Seek(0, io.SeekCurrent); err == nil && info.Size() < offset {
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		f.partial = nil
+	}
+
+	return lines, nil
+}
+
+func (f *Follower) read() ([]string, error) {
+	data, err := io.ReadAll(f.file)
+	if err != nil {
+		return nil, err
+	}
+
+	data = append(f.partial, data...)
+	end := strings.LastIndexByte(string(data), '\n')
+	if end < 0 {
+		f.partial = data
+		return nil, nil
+	}
+
+	f.partial = append([]byte(nil), data[end+1:]...)
+	return strings.Split(string(data[:end]), "\n"), nil
+}
+
+// Flush returns a trailing line that has no line break yet.
+func (f *Follower) Flush() []string {
+	if len(f.partial) == 0 {
+		return nil
+	}
+	line := string(f.partial)
+	f.partial = nil
+	return []string{line}
+}
+
+func (f *Follower) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
+
+// Tail returns up to n of the last lines logged at path, including rotated
+// files when the current one is shorter.
+func Tail(path string, n int) []string {
+	var lines []string
+
+	files := Files(path)
+	for i := len(files) - 1; i >= 0 && len(lines) < n; i-- {
+		data, err := os.ReadFile(files[i])
+		if err != nil {
+			continue
+		}
+		text := strings.TrimRight(string(data), "\n")
+		if text == "" {
+			continue
+		}
+		lines = append(strings.Split(text, "\n"), lines...)
+	}
+
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
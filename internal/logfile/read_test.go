@@ -0,0 +1,180 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "umono.log")
+	for _, name := range []string{"umono.log", "umono.log.1", "umono.log.2", "umono.log.10", "umono.log.old"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{path + ".10", path + ".2", path + ".1", path}
+	if got := Files(path); !reflect.DeepEqual(got, want) {
+		t.Errorf("Files() = %v, want %v", got, want)
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		text      string
+		wantTime  string
+		wantLevel string
+	}{
+		{`{"time":"2024-05-01T10:00:00Z","level":"warn","msg":"slow"}`, "2024-05-01T10:00:00Z", "WARN"},
+		{`{"ts":1714557600,"severity":"error","message":"boom"}`, "2024-05-01T10:00:00Z", "ERROR"},
+		{"2024-05-01T10:00:00Z umono-cli: server exited", "2024-05-01T10:00:00Z", ""},
+		{"goroutine 1 [running]:", "", ""},
+	}
+
+	for _, tt := range tests {
+		line := ParseLine(tt.text)
+		gotTime := ""
+		if !line.Time.IsZero() {
+			gotTime = line.Time.UTC().Format(time.RFC3339)
+		}
+		if gotTime != tt.wantTime {
+			t.Errorf("ParseLine(%q).Time = %q, want %q", tt.text, gotTime, tt.wantTime)
+		}
+		if got := line.Level(); got != tt.wantLevel {
+			t.Errorf("ParseLine(%q).Level() = %q, want %q", tt.text, got, tt.wantLevel)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	lines := []string{
+		"2024-05-01T09:00:00Z old",
+		"old trace",
+		"2024-05-01T11:00:00Z new error",
+		"new trace",
+		"2024-05-01T11:30:00Z new info",
+	}
+
+	filter := &Filter{
+		Since:   time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Pattern: regexp.MustCompile("new"),
+	}
+
+	var got []string
+	for _, text := range lines {
+		if filter.Match(ParseLine(text)) {
+			got = append(got, text)
+		}
+	}
+
+	want := []string{"2024-05-01T11:00:00Z new error", "new trace", "2024-05-01T11:30:00Z new info"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("matched %v, want %v", got, want)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	got, err := ParseSince("90m", now)
+	if err != nil || !got.Equal(now.Add(-90*time.Minute)) {
+		t.Errorf("ParseSince(90m) = %v, %v", got, err)
+	}
+
+	if _, err := ParseSince("2024-04-30", now); err != nil {
+		t.Errorf("ParseSince(date) error = %v", err)
+	}
+
+	if _, err := ParseSince("yesterday", now); err == nil {
+		t.Error("ParseSince(yesterday) expected error")
+	}
+}
+
+func TestFollower_AcrossRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "umono.log")
+
+	w, err := Open(Options{Path: path, MaxSize: 20, MaxFiles: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("one\n"))
+
+	f, err := NewFollower(path)
+	if err != nil {
+		t.Fatalf("NewFollower() error = %v", err)
+	}
+	defer f.Close()
+
+	var got []string
+	poll := func() {
+		lines, err := f.Poll()
+		if err != nil {
+			t.Fatalf("Poll() error = %v", err)
+		}
+		got = append(got, lines...)
+	}
+
+	poll()
+	w.Write([]byte("two\n"))
+	w.Write([]byte("par"))
+	poll()
+	w.Write([]byte("tial\n"))
+	// Rotates, so this line starts the new file.
+	w.Write([]byte("four and more\n"))
+	poll()
+	w.Write([]byte("five\n"))
+	poll()
+
+	want := []string{"one", "two", "partial", "four and more", "five"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("followed %q, want %q", got, want)
+	}
+}
+
+func TestTail(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "umono.log")
+	os.WriteFile(path+".1", []byte("a\nb\nc\n"), 0o644)
+	os.WriteFile(path, []byte("d\n"), 0o644)
+
+	if got, want := Tail(path, 3), []string{"b", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tail(3) = %v, want %v", got, want)
+	}
+	if got, want := Tail(path, 10), []string{"a", "b", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tail(10) = %v, want %v", got, want)
+	}
+}
+
+func TestFollower_WaitsForMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "umono.log")
+
+	f, err := NewFollower(path)
+	if err != nil {
+		t.Fatalf("NewFollower() error = %v", err)
+	}
+	defer f.Close()
+
+	lines, err := f.Poll()
+	if err != nil || len(lines) != 0 {
+		t.Fatalf("Poll() = %q, %v; want nothing", lines, err)
+	}
+
+	if err := os.WriteFile(path, []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err = f.Poll()
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if want := []string{"one"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("Poll() = %q, want %q", lines, want)
+	}
+}
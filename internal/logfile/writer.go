@@ -0,0 +1,197 @@
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultPath     = "logs/umono.log"
+	DefaultMaxSize  = 10 * 1024 * 1024
+	DefaultMaxAge   = 7 * 24 * time.Hour
+	DefaultMaxFiles = 5
+)
+
+type Options struct {
+	Path string
+	// MaxSize is the size in bytes after which the file is rotated. Zero
+	// disables size-based rotation.
+	MaxSize int64
+	// MaxAge is how long a file is written to before it is rotated. Zero
+	// disables age-based rotation.
+	MaxAge time.Duration
+	// MaxFiles is the number of rotated files kept next to the current one.
+	MaxFiles int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Path:     DefaultPath,
+		MaxSize:  DefaultMaxSize,
+		MaxAge:   DefaultMaxAge,
+		MaxFiles: DefaultMaxFiles,
+	}
+}
+
+// Writer appends to a log file and rotates it by size and age. Rotated files
+// are named <path>.1 (newest) to <path>.<MaxFiles> (oldest).
+//
+// Rotation happens before a write that would cross a limit, so callers that
+// write whole lines never split a line across files.
+type Writer struct {
+	mu     sync.Mutex
+	opts   Options
+	file   *os.File
+	closed bool
+	size   int64
+	// base is the size at which a rotation last failed. Only bytes written
+	// since then count towards MaxSize, so a file that could not be rotated
+	// is kept for another full window instead of being retried on every
+	// write.
+	base     int64
+	openedAt time.Time
+	now      func() time.Time
+}
+
+func Open(opts Options) (*Writer, error) {
+	w := &Writer{opts: opts, now: time.Now}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.opts.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(w.opts.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	w.file = file
+	w.size = info.Size()
+	w.base = 0
+	w.openedAt = w.now()
+	// An existing file is treated as started at its last write, which is
+	// the closest approximation of its age that is available everywhere.
+	if w.size > 0 && info.ModTime().Before(w.openedAt) {
+		w.openedAt = info.ModTime()
+	}
+
+	return nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	// A failed reopen after rotation is retried on every write, so output
+	// resumes as soon as the file can be opened again.
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	// When rotation fails, p still goes to the reopened file and the next
+	// attempt waits for another size or age window.
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			if w.file == nil {
+				return 0, err
+			}
+			w.base = w.size
+			w.openedAt = w.now()
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *Writer) shouldRotate(next int64) bool {
+	if w.size == w.base {
+		return false
+	}
+	if w.opts.MaxSize > 0 && w.size-w.base+next > w.opts.MaxSize {
+		return true
+	}
+	if w.opts.MaxAge > 0 && w.now().Sub(w.openedAt) >= w.opts.MaxAge {
+		return true
+	}
+	return false
+}
+
+// Rotate starts a new log file immediately.
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.rotate()
+}
+
+// rotate always reopens the current path, even when moving the old file
+// aside failed, so that a failed rotation never stops logging. A missing
+// current file, e.g. one already moved away by logrotate before SIGHUP, counts
+// as rotated.
+func (w *Writer) rotate() error {
+	var rotateErr error
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			rotateErr = fmt.Errorf("failed to close log file: %w", err)
+		}
+		w.file = nil
+	}
+
+	var err error
+	if w.opts.MaxFiles > 0 {
+		os.Remove(backupName(w.opts.Path, w.opts.MaxFiles))
+		for i := w.opts.MaxFiles - 1; i >= 1; i-- {
+			os.Rename(backupName(w.opts.Path, i), backupName(w.opts.Path, i+1))
+		}
+		err = os.Rename(w.opts.Path, backupName(w.opts.Path, 1))
+	} else {
+		err = os.Remove(w.opts.Path)
+	}
+	if err != nil && !os.IsNotExist(err) && rotateErr == nil {
+		rotateErr = fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	if err := w.open(); err != nil {
+		return err
+	}
+	return rotateErr
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func backupName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
@@ -0,0 +1,156 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestWriter_RotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "umono.log")
+
+	w, err := Open(Options{Path: path, MaxSize: 10, MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for file, content := range want {
+		if got := readFile(t, file); got != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(file), got, content)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 rotated files to be kept")
+	}
+}
+
+func TestWriter_RotatesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "umono.log")
+
+	now := time.Now()
+	w, err := Open(Options{Path: path, MaxAge: time.Hour, MaxFiles: 1})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer w.Close()
+	w.now = func() time.Time { return now }
+	w.openedAt = now
+
+	w.Write([]byte("old\n"))
+	now = now.Add(30 * time.Minute)
+	w.Write([]byte("still current\n"))
+	now = now.Add(time.Hour)
+	w.Write([]byte("new\n"))
+
+	if got := readFile(t, path); got != "new\n" {
+		t.Errorf("current log = %q, want %q", got, "new\n")
+	}
+	if got := readFile(t, path+".1"); got != "old\nstill current\n" {
+		t.Errorf("rotated log = %q", got)
+	}
+}
+
+func TestWriter_AppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "umono.log")
+	if err := os.WriteFile(path, []byte("before\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := Open(Options{Path: path, MaxSize: 1024})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	w.Write([]byte("after\n"))
+	w.Close()
+
+	if got := readFile(t, path); !strings.HasSuffix(got, "before\nafter\n") {
+		t.Errorf("log = %q, want appended", got)
+	}
+}
+
+func TestWriter_KeepsLoggingAfterFailedRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "umono.log")
+
+	w, err := Open(Options{Path: path, MaxFiles: 1})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("before\n"))
+
+	// logrotate moves the file away and then sends SIGHUP.
+	if err := os.Rename(path, path+".moved"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Rotate(); err != nil {
+		t.Errorf("Rotate() after the file was moved away error = %v", err)
+	}
+	w.Write([]byte("after move\n"))
+
+	// A backup that cannot be replaced makes the rotation itself fail.
+	os.Remove(path + ".1")
+	if err := os.MkdirAll(filepath.Join(path+".1", "busy"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Rotate(); err == nil {
+		t.Error("Rotate() error = nil, want the failed rename")
+	}
+	if _, err := w.Write([]byte("after failure\n")); err != nil {
+		t.Fatalf("Write() after a failed rotation error = %v", err)
+	}
+
+	if got, want := readFile(t, path), "after move\nafter failure\n"; got != want {
+		t.Errorf("current file = %q, want %q", got, want)
+	}
+}
+
+func TestWriter_KeepsWritingWhenSizeRotationFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "umono.log")
+
+	// A backup that cannot be replaced makes every rotation fail.
+	if err := os.MkdirAll(filepath.Join(path+".1", "busy"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := Open(Options{Path: path, MaxSize: 10, MaxFiles: 1})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer w.Close()
+
+	lines := []string{"first\n", "second\n", "third\n"}
+	for _, line := range lines {
+		if n, err := w.Write([]byte(line)); n != len(line) {
+			t.Fatalf("Write(%q) = %d, %v; want the line written", line, n, err)
+		}
+	}
+
+	if got, want := readFile(t, path), strings.Join(lines, ""); got != want {
+		t.Errorf("current file = %q, want %q", got, want)
+	}
+}
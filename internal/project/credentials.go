@@ -0,0 +1,69 @@
+package project
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+
+	"github.com/umono-cms/cli/internal/confed"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SetCredentials replaces the root account hashes in the project's .env. An
+// empty username keeps the current one. Plaintext USERNAME and PASSWORD
+// values are cleared.
+func SetCredentials(projectPath, username, password string) error {
+	envPath := filepath.Join(projectPath, ".env")
+
+	env := confed.NewEnvEditor()
+	if err := env.Read(envPath); err != nil {
+		return fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	if username != "" {
+		hashedUsername, err := hashData(username)
+		if err != nil {
+			return fmt.Errorf("failed to hash Username: %w", err)
+		}
+		env.SetValue("HASHED_USERNAME", base64.StdEncoding.EncodeToString([]byte(hashedUsername)))
+	}
+
+	hashedPassword, err := hashData(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash Password: %w", err)
+	}
+	env.SetValue("HASHED_PASSWORD", base64.StdEncoding.EncodeToString([]byte(hashedPassword)))
+
+	for _, key := range []string{"USERNAME", "PASSWORD"} {
+		if _, ok := env.GetValue(key); ok {
+			env.SetValue(key, "")
+		}
+	}
+
+	if err := env.Write(envPath); err != nil {
+		return fmt.Errorf("failed to write .env: %w", err)
+	}
+
+	return nil
+}
+
+// MatchesUsername reports whether value is the project's current root
+// username.
+func MatchesUsername(projectPath, value string) bool {
+	env := confed.NewEnvEditor()
+	if err := env.Read(filepath.Join(projectPath, ".env")); err != nil {
+		return false
+	}
+
+	encoded, ok := env.GetValue("HASHED_USERNAME")
+	if !ok {
+		return false
+	}
+
+	hash, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return false
+	}
+
+	return bcrypt.CompareHashAndPassword(hash, []byte(value)) == nil
+}
@@ -0,0 +1,70 @@
+package project
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/umono-cms/cli/internal/confed"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestSetCredentials(t *testing.T) {
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, ".env")
+	content := "PORT=8999\nUSERNAME=admin\nPASSWORD=secret\nHASHED_USERNAME=old\nHASHED_PASSWORD=old\n"
+	if err := os.WriteFile(envPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetCredentials(dir, "", "new-password"); err != nil {
+		t.Fatalf("SetCredentials() error = %v", err)
+	}
+
+	env := confed.NewEnvEditor()
+	if err := env.Read(envPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := env.GetValue("HASHED_USERNAME"); got != "old" {
+		t.Errorf("HASHED_USERNAME = %q, want it kept when no username is given", got)
+	}
+
+	for _, key := range []string{"USERNAME", "PASSWORD"} {
+		if got, ok := env.GetValue(key); !ok || got != "" {
+			t.Errorf("%s = %q, %v; want cleared", key, got, ok)
+		}
+	}
+
+	encoded, _ := env.GetValue("HASHED_PASSWORD")
+	hash, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("HASHED_PASSWORD is not base64: %v", err)
+	}
+	if err := bcrypt.CompareHashAndPassword(hash, []byte("new-password")); err != nil {
+		t.Errorf("HASHED_PASSWORD does not match the new password: %v", err)
+	}
+
+	if got, _ := env.GetValue("PORT"); got != "8999" {
+		t.Errorf("PORT = %q, want untouched", got)
+	}
+}
+
+func TestMatchesUsername(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("PORT=8999\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetCredentials(dir, "admin", "correct-horse"); err != nil {
+		t.Fatalf("SetCredentials() error = %v", err)
+	}
+
+	if !MatchesUsername(dir, "admin") {
+		t.Error("MatchesUsername(admin) = false, want true")
+	}
+	if MatchesUsername(dir, "someone") {
+		t.Error("MatchesUsername(someone) = true, want false")
+	}
+}
@@ -2,15 +2,21 @@ package project
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/umono-cms/cli/internal/compatibility"
 	"github.com/umono-cms/cli/internal/confed"
+	"github.com/umono-cms/cli/internal/config"
 	"github.com/umono-cms/cli/internal/download"
+	"github.com/umono-cms/cli/internal/receipt"
+	"github.com/umono-cms/cli/internal/signature"
+	"github.com/umono-cms/cli/internal/trust"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -19,26 +25,53 @@ type Project struct {
 	Password string
 	Path     string
 	Port     string
+	Strict   bool
+	// Version is a version constraint such as "^1.2"; empty selects the
+	// latest release.
+	Version string
+}
+
+type UpgradeOptions struct {
+	Strict  bool
+	Version string
 }
 
 func Create(cmd *cobra.Command, project Project) error {
-	client := download.NewClient()
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
 
-	result, err := compatibility.Check(client)
+	tag, err := selectRelease(client, project.Version)
 	if err != nil {
-		return fmt.Errorf("failed to check compatibility: %w", err)
+		return err
 	}
 
-	if !result.Compatible {
-		return fmt.Errorf(compatibility.FormatIncompatibleError(result))
+	result, err := checkCompatibility(client, tag, project.Strict, compatibility.CurrentEnvironment())
+	if err != nil {
+		return err
 	}
 
-	releaseInfo, err := client.GetLatestRelease()
+	releaseInfo, err := getRelease(client, tag)
 	if err != nil {
 		return fmt.Errorf("failed to fetch release: %w", err)
 	}
 
-	if err := client.DownloadAndExtract(releaseInfo, project.Path); err != nil {
+	archive, err := downloadRelease(client, releaseInfo, project.Path, project.Strict)
+	if err != nil {
+		return err
+	}
+
+	rec := &receipt.Receipt{
+		Version:       archive.Version,
+		AssetName:     archive.AssetName,
+		AssetHash:     archive.Hash,
+		InstalledAt:   time.Now().UTC(),
+		SchemaVersion: result.Manifest.SchemaVersion(),
+		Manifest:      recordedManifest(result.Manifest),
+		Files:         archive.Files,
+	}
+	if err := rec.Save(project.Path); err != nil {
 		return err
 	}
 
@@ -73,29 +106,37 @@ func Create(cmd *cobra.Command, project Project) error {
 	return nil
 }
 
-func Upgrade(projectPath string) error {
-	binaryPath := findBinaryPath(projectPath)
+func Upgrade(projectPath string, opts UpgradeOptions) error {
+	binaryPath := FindBinary(projectPath)
 	if binaryPath == "" {
 		return fmt.Errorf("no Umono binary found in %s", projectPath)
 	}
 
-	client := download.NewClient()
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
 
-	result, err := compatibility.Check(client)
+	tag, err := selectRelease(client, opts.Version)
 	if err != nil {
-		return fmt.Errorf("failed to check compatibility: %w", err)
+		return err
 	}
 
-	if !result.Compatible {
-		return fmt.Errorf(compatibility.FormatIncompatibleError(result))
+	result, err := checkCompatibility(client, tag, opts.Strict, projectEnvironment(projectPath))
+	if err != nil {
+		return err
 	}
 
-	releaseInfo, err := client.GetLatestRelease()
+	releaseInfo, err := getRelease(client, tag)
 	if err != nil {
 		return fmt.Errorf("failed to fetch release: %w", err)
 	}
 
-	fmt.Printf("📦 Latest version: %s\n", releaseInfo.Version)
+	if tag == "" {
+		fmt.Printf("📦 Latest version: %s\n", releaseInfo.Version)
+	} else {
+		fmt.Printf("📦 Selected version: %s (matches %s)\n", releaseInfo.Version, opts.Version)
+	}
 
 	tmpDir, err := os.MkdirTemp("", "umono-upgrade-*")
 	if err != nil {
@@ -103,11 +144,12 @@ func Upgrade(projectPath string) error {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	if err := client.DownloadAndExtract(releaseInfo, tmpDir); err != nil {
+	archive, err := downloadRelease(client, releaseInfo, tmpDir, opts.Strict)
+	if err != nil {
 		return err
 	}
 
-	newBinaryPath := findBinaryPath(tmpDir)
+	newBinaryPath := FindBinary(tmpDir)
 	if newBinaryPath == "" {
 		return fmt.Errorf("no binary found in downloaded release")
 	}
@@ -124,10 +166,190 @@ func Upgrade(projectPath string) error {
 
 	os.Remove(backupPath)
 
-	return nil
+	return updateReceipt(projectPath, binaryPath, tmpDir, newBinaryPath, archive, result.Manifest)
+}
+
+// updateReceipt records the upgraded binary. Only the binary is replaced on
+// upgrade, so the other recorded files keep their original hashes. Without
+// an existing receipt only the binary can be recorded.
+func updateReceipt(projectPath, binaryPath, releaseDir, newBinaryPath string, archive *download.Archive, manifest *download.Manifest) error {
+	rec, err := receipt.Load(projectPath)
+	if os.IsNotExist(err) {
+		rec = &receipt.Receipt{Files: make(map[string]string), BinaryOnly: true}
+	} else if err != nil {
+		return err
+	}
+
+	installedRel, err := filepath.Rel(projectPath, binaryPath)
+	if err != nil {
+		return err
+	}
+	releaseRel, err := filepath.Rel(releaseDir, newBinaryPath)
+	if err != nil {
+		return err
+	}
+
+	rec.Version = archive.Version
+	rec.AssetName = archive.AssetName
+	rec.AssetHash = archive.Hash
+	rec.InstalledAt = time.Now().UTC()
+	if schema := manifest.SchemaVersion(); schema > 0 {
+		rec.SchemaVersion = schema
+	}
+	rec.Manifest = recordedManifest(manifest)
+	rec.Files[filepath.ToSlash(installedRel)] = archive.Files[filepath.ToSlash(releaseRel)]
+
+	return rec.Save(projectPath)
+}
+
+// selectRelease resolves a version constraint to the newest release tag
+// satisfying it. An empty constraint selects the latest release and returns
+// an empty tag.
+func selectRelease(client *download.Client, constraint string) (string, error) {
+	if constraint == "" {
+		return "", nil
+	}
+
+	c, err := compatibility.ParseConstraint(constraint)
+	if err != nil {
+		return "", err
+	}
+
+	tags, err := client.ListReleaseTags()
+	if err != nil {
+		return "", fmt.Errorf("failed to list releases: %w", err)
+	}
+
+	var best compatibility.Version
+	bestTag := ""
+	for _, tag := range tags {
+		v, err := compatibility.ParseVersion(tag)
+		if err != nil || !c.Check(v) {
+			continue
+		}
+		if bestTag == "" || best.LessThan(v) {
+			best, bestTag = v, tag
+		}
+	}
+
+	if bestTag == "" {
+		return "", fmt.Errorf("no release matches version %s", constraint)
+	}
+
+	return bestTag, nil
+}
+
+func getRelease(client *download.Client, tag string) (*download.ReleaseInfo, error) {
+	if tag == "" {
+		return client.GetLatestRelease()
+	}
+	return client.GetReleaseByTag(tag)
+}
+
+func checkCompatibility(client *download.Client, tag string, strict bool, env compatibility.Environment) (*compatibility.CheckResult, error) {
+	var result *compatibility.CheckResult
+	var err error
+	if tag == "" {
+		result, err = compatibility.Check(client, env)
+	} else {
+		result, err = compatibility.CheckForVersion(client, tag, env)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check compatibility: %w", err)
+	}
+
+	if !result.Compatible {
+		return nil, errors.New(compatibility.FormatIncompatibleError(result))
+	}
+
+	if strict && result.ManifestMissing {
+		return nil, fmt.Errorf("strict verification enabled but release %s has no manifest (umono.json)", result.UmonoVersion)
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Printf("⚠️  Warning: %s\n", warning)
+	}
+
+	if len(result.PendingMigrations) > 0 {
+		fmt.Println("🗄️  Data migrations will run on the next start:")
+		for _, migration := range result.PendingMigrations {
+			if migration.Description != "" {
+				fmt.Printf("   - %s: %s\n", migration.ID, migration.Description)
+			} else {
+				fmt.Printf("   - %s\n", migration.ID)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// projectEnvironment describes an existing installation for compatibility
+// checks. Missing .env or receipt information is left empty.
+func projectEnvironment(projectPath string) compatibility.Environment {
+	env := compatibility.CurrentEnvironment()
+	env.Project = &compatibility.ProjectEnvironment{}
+
+	envEditor := confed.NewEnvEditor()
+	if err := envEditor.Read(filepath.Join(projectPath, ".env")); err == nil {
+		env.Project.EnvKeys = envEditor.Keys()
+	}
+
+	if rec, err := receipt.Load(projectPath); err == nil {
+		env.Project.SchemaVersion = rec.SchemaVersion
+	}
+
+	return env
+}
+
+func recordedManifest(manifest *download.Manifest) *download.Manifest {
+	if manifest.Missing {
+		return nil
+	}
+	return manifest
+}
+
+func downloadRelease(client *download.Client, info *download.ReleaseInfo, destDir string, strict bool) (*download.Archive, error) {
+	if strict {
+		return client.DownloadAndExtractWithStrictVerification(info, destDir)
+	}
+	return client.DownloadAndExtract(info, destDir)
+}
+
+func newClient() (*download.Client, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := download.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range cfg.TrustedKeys {
+		key, err := signature.ParsePublicKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted key in config: %w", err)
+		}
+		client.AddTrustedKey(key)
+	}
+
+	pinPath, err := trust.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	pins, err := trust.Load(pinPath)
+	if err != nil {
+		return nil, err
+	}
+	client.SetPinStore(pins)
+
+	return client, nil
 }
 
-func findBinaryPath(dir string) string {
+// FindBinary returns the Umono binary in dir, which may still carry the
+// platform suffix of its release asset, or "" when there is none.
+func FindBinary(dir string) string {
 	candidates := []string{"umono", "umono-darwin-amd64", "umono-darwin-arm64", "umono-linux-amd64", "umono-linux-arm64"}
 
 	for _, name := range candidates {
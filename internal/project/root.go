@@ -0,0 +1,52 @@
+package project
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/umono-cms/cli/internal/receipt"
+)
+
+// ErrNotFound is returned by Find when neither the starting directory nor
+// any of its parents is an Umono project.
+var ErrNotFound = errors.New("not an Umono project (or any of the parent directories)")
+
+// IsRoot reports whether dir is the root of an Umono project: it holds the
+// umono binary next to a .env or the .umono metadata directory. The binary
+// alone is not enough, since the CLI itself is installed as ~/.local/bin/umono.
+func IsRoot(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "umono"))
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, ".env")); err == nil && !info.IsDir() {
+		return true
+	}
+	if info, err := os.Stat(filepath.Join(dir, receipt.Dir)); err == nil && info.IsDir() {
+		return true
+	}
+	return false
+}
+
+// Find walks up from start to the nearest project root, like git does for
+// its repository.
+func Find(start string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if IsRoot(dir) {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrNotFound
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,70 @@
+package project
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	site := filepath.Join(base, "site")
+	write(filepath.Join(site, "umono"))
+	write(filepath.Join(site, ".env"))
+
+	legacy := filepath.Join(base, "legacy")
+	write(filepath.Join(legacy, "umono"))
+	write(filepath.Join(legacy, ".umono", "receipt.json"))
+
+	bin := filepath.Join(base, "home", ".local", "bin")
+	write(filepath.Join(bin, "umono"))
+
+	tests := []struct {
+		name  string
+		start string
+		want  string
+	}{
+		{"project root", site, site},
+		{"nested directory", filepath.Join(site, "uploads", "images"), site},
+		{"metadata directory marks the root", filepath.Join(legacy, ".umono"), legacy},
+		{"binary alone is not a project", bin, ""},
+		{"outside any project", base, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.MkdirAll(tt.start, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Find(tt.start)
+			if tt.want == "" {
+				if !errors.Is(err, ErrNotFound) {
+					t.Fatalf("Find() = %q, %v; want ErrNotFound", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Find() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package project
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/umono-cms/cli/internal/checksum"
+	"github.com/umono-cms/cli/internal/confed"
+	"github.com/umono-cms/cli/internal/config"
+	"github.com/umono-cms/cli/internal/receipt"
+)
+
+type VerifyOptions struct {
+	// Offline skips checking the recorded archive against the release's
+	// published checksums.txt.
+	Offline bool
+}
+
+type VerifyReport struct {
+	Version string
+	// BinaryOnly is set when only the binary is recorded, so other files
+	// were not checked.
+	BinaryOnly bool
+	Modified   []string
+	Missing    []string
+	Extra      []string
+
+	// ReleaseError is set when the recorded archive no longer matches the
+	// release's checksums.txt, or the release could not be checked.
+	ReleaseError error
+}
+
+func (r *VerifyReport) OK() bool {
+	return len(r.Modified) == 0 && len(r.Missing) == 0 && len(r.Extra) == 0 && r.ReleaseError == nil
+}
+
+func Verify(projectPath string, opts VerifyOptions) (*VerifyReport, error) {
+	rec, err := receipt.Load(projectPath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no install receipt found (%s); run 'umono upgrade' to record at least the umono binary", filepath.Join(receipt.Dir, receipt.FileName))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VerifyReport{Version: rec.Version, BinaryOnly: rec.BinaryOnly}
+
+	for name, expected := range rec.Files {
+		actual, err := checksum.CalculateFileSHA256(filepath.Join(projectPath, filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			report.Missing = append(report.Missing, name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", name, err)
+		}
+
+		if actual != expected {
+			report.Modified = append(report.Modified, name)
+		}
+	}
+
+	// Without the release's file list, every shipped file would look extra.
+	if !rec.BinaryOnly {
+		report.Extra, err = extraFiles(projectPath, rec)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(report.Modified)
+	sort.Strings(report.Missing)
+	sort.Strings(report.Extra)
+
+	if !opts.Offline {
+		report.ReleaseError = verifyReleaseChecksum(rec)
+	}
+
+	return report, nil
+}
+
+// extraFiles lists the files in the project that are neither recorded in the
+// receipt nor runtime data.
+func extraFiles(projectPath string, rec *receipt.Receipt) ([]string, error) {
+	isRuntime := runtimeFilter(projectPath)
+	var extra []string
+	err := filepath.WalkDir(projectPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == projectPath {
+			return nil
+		}
+
+		rel, err := filepath.Rel(projectPath, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+
+		if isRuntime(name) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		if _, ok := rec.Files[name]; !ok {
+			extra = append(extra, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan project: %w", err)
+	}
+
+	return extra, nil
+}
+
+// runtimeEntries are created in the project root by the CLI and by Umono
+// while it runs, and are never reported as extra files.
+var runtimeEntries = []string{".env", receipt.Dir, "logs", "uploads", ".PID"}
+
+// runtimeFilter reports whether a project-relative path belongs to the
+// project's runtime data rather than to the release: runtimeEntries, the
+// database named by DSN with its journal files, and the configured log file
+// with its rotated copies.
+func runtimeFilter(projectPath string) func(name string) bool {
+	exact := make(map[string]bool)
+	for _, name := range runtimeEntries {
+		exact[name] = true
+	}
+
+	var prefixes []string
+	env := confed.NewEnvEditor()
+	if err := env.Read(filepath.Join(projectPath, ".env")); err == nil {
+		if dsn, _ := env.GetValue("DSN"); dsn != "" {
+			dsn = strings.TrimPrefix(dsn, "file:")
+			if i := strings.IndexByte(dsn, '?'); i >= 0 {
+				dsn = dsn[:i]
+			}
+			if rel, ok := projectRelative(projectPath, dsn); ok {
+				prefixes = append(prefixes, rel)
+			}
+		}
+	}
+	if cfg, err := config.Load(); err == nil {
+		if rel, ok := projectRelative(projectPath, cfg.Logs.Options(projectPath).Path); ok {
+			prefixes = append(prefixes, rel)
+		}
+	}
+
+	return func(name string) bool {
+		if exact[name] {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// projectRelative returns path relative to the project in slash form, if it
+// lies inside it.
+func projectRelative(projectPath, path string) (string, bool) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectPath, path)
+	}
+	rel, err := filepath.Rel(projectPath, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func verifyReleaseChecksum(rec *receipt.Receipt) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	info, err := client.GetReleaseByTag(rec.Version)
+	if err != nil {
+		return err
+	}
+
+	verifier, err := client.LoadChecksums(info)
+	if err != nil {
+		return err
+	}
+
+	return verifier.VerifyHash(rec.AssetName, rec.AssetHash)
+}
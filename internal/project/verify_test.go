@@ -0,0 +1,131 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/umono-cms/cli/internal/receipt"
+)
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+
+	rec := &receipt.Receipt{
+		Version: "v1.0.0",
+		Files: map[string]string{
+			"umono":           sha256Hex("binary"),
+			"public/app.js":   sha256Hex("app"),
+			"public/app.css":  sha256Hex("css"),
+			"public/logo.svg": sha256Hex("logo"),
+		},
+	}
+	if err := rec.Save(dir); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "umono"), "binary")
+	writeFile(t, filepath.Join(dir, "public", "app.js"), "app")
+	writeFile(t, filepath.Join(dir, "public", "app.css"), "tampered")
+	writeFile(t, filepath.Join(dir, "public", "extra.js"), "injected")
+	writeFile(t, filepath.Join(dir, ".env"), "PORT=8999\n")
+
+	report, err := Verify(dir, VerifyOptions{Offline: true})
+	if err != nil {
+		t.Fatalf("Verify() unexpected error: %v", err)
+	}
+
+	if want := []string{"public/app.css"}; !reflect.DeepEqual(report.Modified, want) {
+		t.Errorf("Modified = %v, want %v", report.Modified, want)
+	}
+	if want := []string{"public/logo.svg"}; !reflect.DeepEqual(report.Missing, want) {
+		t.Errorf("Missing = %v, want %v", report.Missing, want)
+	}
+	if want := []string{"public/extra.js"}; !reflect.DeepEqual(report.Extra, want) {
+		t.Errorf("Extra = %v, want %v", report.Extra, want)
+	}
+	if report.OK() {
+		t.Error("OK() = true for a drifted installation")
+	}
+}
+
+func TestVerify_NoReceipt(t *testing.T) {
+	if _, err := Verify(t.TempDir(), VerifyOptions{Offline: true}); err == nil {
+		t.Error("Verify() expected error without an install receipt")
+	}
+}
+
+func TestVerify_ProjectRoot(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+
+	rec := &receipt.Receipt{
+		Version: "v1.0.0",
+		Files:   map[string]string{"umono": sha256Hex("binary")},
+	}
+	if err := rec.Save(dir); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "umono"), "binary")
+	writeFile(t, filepath.Join(dir, ".env"), "PORT=8999\nDSN=data/site.db\n")
+	writeFile(t, filepath.Join(dir, "data", "site.db"), "db")
+	writeFile(t, filepath.Join(dir, "data", "site.db-wal"), "wal")
+	writeFile(t, filepath.Join(dir, "logs", "umono.log"), "log")
+	writeFile(t, filepath.Join(dir, "uploads", "logo.png"), "png")
+	writeFile(t, filepath.Join(dir, ".umono", "state.json"), "{}")
+	writeFile(t, filepath.Join(dir, "backdoor.php"), "injected")
+	writeFile(t, filepath.Join(dir, "data", "notes.txt"), "unexpected")
+
+	report, err := Verify(dir, VerifyOptions{Offline: true})
+	if err != nil {
+		t.Fatalf("Verify() unexpected error: %v", err)
+	}
+
+	if want := []string{"backdoor.php", "data/notes.txt"}; !reflect.DeepEqual(report.Extra, want) {
+		t.Errorf("Extra = %v, want %v", report.Extra, want)
+	}
+}
+
+func TestVerify_BinaryOnlyReceipt(t *testing.T) {
+	dir := t.TempDir()
+
+	rec := &receipt.Receipt{
+		Version:    "v1.1.0",
+		Files:      map[string]string{"umono": sha256Hex("binary")},
+		BinaryOnly: true,
+	}
+	if err := rec.Save(dir); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "umono"), "binary")
+	writeFile(t, filepath.Join(dir, "public", "app.js"), "shipped by an older release")
+
+	report, err := Verify(dir, VerifyOptions{Offline: true})
+	if err != nil {
+		t.Fatalf("Verify() unexpected error: %v", err)
+	}
+	if !report.OK() || !report.BinaryOnly {
+		t.Errorf("Verify() = %+v, want only the binary checked", report)
+	}
+}
@@ -0,0 +1,81 @@
+package receipt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/umono-cms/cli/internal/download"
+)
+
+const (
+	Dir      = ".umono"
+	FileName = "receipt.json"
+)
+
+// Receipt records what the CLI installed into a project, so the installation
+// can later be checked for tampering or drift.
+type Receipt struct {
+	Version     string    `json:"version"`
+	AssetName   string    `json:"asset_name"`
+	AssetHash   string    `json:"asset_hash"`
+	InstalledAt time.Time `json:"installed_at"`
+
+	// SchemaVersion is the data schema version declared by the release's
+	// manifest, 0 when it declares none.
+	SchemaVersion int `json:"schema_version,omitempty"`
+
+	// Manifest is the installed release's manifest, nil when the release
+	// published none.
+	Manifest *download.Manifest `json:"manifest,omitempty"`
+
+	// Files maps slash-separated paths relative to the project root to their
+	// SHA-256.
+	Files map[string]string `json:"files"`
+
+	// BinaryOnly is set when the receipt was first written by an upgrade of
+	// a project created without one. Upgrades only replace the binary, so
+	// the other files cannot be attributed to a release.
+	BinaryOnly bool `json:"binary_only,omitempty"`
+}
+
+func Path(projectPath string) string {
+	return filepath.Join(projectPath, Dir, FileName)
+}
+
+func Load(projectPath string) (*Receipt, error) {
+	data, err := os.ReadFile(Path(projectPath))
+	if err != nil {
+		return nil, err
+	}
+
+	var r Receipt
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse install receipt: %w", err)
+	}
+
+	if r.Files == nil {
+		r.Files = make(map[string]string)
+	}
+
+	return &r, nil
+}
+
+func (r *Receipt) Save(projectPath string) error {
+	if err := os.MkdirAll(filepath.Join(projectPath, Dir), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", Dir, err)
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(Path(projectPath), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write install receipt: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type Scope string
+
+const (
+	User   Scope = "user"
+	System Scope = "system"
+)
+
+var ErrNoSystemd = errors.New("systemd is not available on this system")
+
+// Unit describes the systemd service generated for a project.
+type Unit struct {
+	Name  string
+	Scope Scope
+	// ProjectPath is the absolute project directory.
+	ProjectPath string
+	// RunAs is the account a system unit runs under; empty means root.
+	RunAs string
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// UnitName derives a stable unit name from the project path. The hash keeps
+// projects with the same directory name apart.
+func UnitName(projectPath string) string {
+	base := strings.Trim(unsafeNameChars.ReplaceAllString(filepath.Base(projectPath), "-"), "-")
+	if base == "" {
+		base = "project"
+	}
+	sum := sha256.Sum256([]byte(projectPath))
+	return fmt.Sprintf("umono-%s-%s.service", base, hex.EncodeToString(sum[:4]))
+}
+
+func NewUnit(projectPath string, scope Scope) *Unit {
+	return &Unit{
+		Name:        UnitName(projectPath),
+		Scope:       scope,
+		ProjectPath: projectPath,
+	}
+}
+
+// Path is where the unit file is installed.
+func (u *Unit) Path() (string, error) {
+	if u.Scope == System {
+		return filepath.Join("/etc/systemd/system", u.Name), nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "systemd", "user", u.Name), nil
+}
+
+func (u *Unit) Render() string {
+	wantedBy := "default.target"
+	if u.Scope == System {
+		wantedBy = "multi-user.target"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\n")
+	fmt.Fprintf(&b, "Description=Umono CMS (%s)\n", u.ProjectPath)
+	fmt.Fprintf(&b, "After=network-online.target\n")
+	fmt.Fprintf(&b, "Wants=network-online.target\n")
+	fmt.Fprintf(&b, "\n[Service]\n")
+	fmt.Fprintf(&b, "Type=simple\n")
+	if u.Scope == System && u.RunAs != "" {
+		fmt.Fprintf(&b, "User=%s\n", u.RunAs)
+	}
+	// Umono reads .env from its working directory, as with 'umono up'.
+	// EnvironmentFile= is not used: systemd does not understand dotenv
+	// quoting, inline comments or ${VAR} references.
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", u.ProjectPath)
+	fmt.Fprintf(&b, "ExecStart=%s\n", quoteExec(filepath.Join(u.ProjectPath, "umono")))
+	fmt.Fprintf(&b, "Restart=on-failure\n")
+	fmt.Fprintf(&b, "RestartSec=5s\n")
+	fmt.Fprintf(&b, "StandardOutput=journal\n")
+	fmt.Fprintf(&b, "StandardError=journal\n")
+	fmt.Fprintf(&b, "SyslogIdentifier=%s\n", strings.TrimSuffix(u.Name, ".service"))
+	fmt.Fprintf(&b, "\n[Install]\n")
+	fmt.Fprintf(&b, "WantedBy=%s\n", wantedBy)
+	return b.String()
+}
+
+func quoteExec(path string) string {
+	if !strings.ContainsAny(path, " \t\"'\\") {
+		return path
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(path) + `"`
+}
+
+// runSystemctl is replaced in tests.
+var runSystemctl = func(args ...string) ([]byte, error) {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return nil, ErrNoSystemd
+	}
+	return exec.Command("systemctl", args...).CombinedOutput()
+}
+
+func (u *Unit) systemctl(args ...string) (string, error) {
+	if u.Scope == User {
+		args = append([]string{"--user"}, args...)
+	}
+
+	out, err := runSystemctl(args...)
+	output := strings.TrimSpace(string(out))
+	if err != nil && output != "" {
+		return output, fmt.Errorf("systemctl %s: %s", strings.Join(args, " "), output)
+	}
+	return output, err
+}
+
+// Install writes the unit file, then enables and starts the service.
+func (u *Unit) Install() error {
+	path, err := u.Path()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(u.Render()), 0o644); err != nil {
+		return fmt.Errorf("failed to write unit file: %w", err)
+	}
+
+	if _, err := u.systemctl("daemon-reload"); err != nil {
+		os.Remove(path)
+		return err
+	}
+	if _, err := u.systemctl("enable", "--now", u.Name); err != nil {
+		os.Remove(path)
+		u.systemctl("daemon-reload")
+		return err
+	}
+	return nil
+}
+
+// Uninstall stops and disables the service and removes the unit file.
+func (u *Unit) Uninstall() error {
+	path, err := u.Path()
+	if err != nil {
+		return err
+	}
+
+	if _, err := u.systemctl("disable", "--now", u.Name); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove unit file: %w", err)
+	}
+	_, err = u.systemctl("daemon-reload")
+	return err
+}
+
+func (u *Unit) Installed() bool {
+	path, err := u.Path()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+func (u *Unit) Start() error {
+	_, err := u.systemctl("start", u.Name)
+	return err
+}
+
+func (u *Unit) Stop() error {
+	_, err := u.systemctl("stop", u.Name)
+	return err
+}
+
+func (u *Unit) Restart() error {
+	_, err := u.systemctl("restart", u.Name)
+	return err
+}
+
+// Status is the state systemd reports for the service.
+type Status struct {
+	// ActiveState is e.g. "active", "inactive" or "failed".
+	ActiveState string
+	SubState    string
+	MainPID     string
+	Enabled     string
+}
+
+func (u *Unit) Status() (*Status, error) {
+	out, err := u.systemctl("show", u.Name, "--property=ActiveState,SubState,MainPID,UnitFileState")
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{}
+	for _, line := range strings.Split(out, "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), "=")
+		switch key {
+		case "ActiveState":
+			status.ActiveState = value
+		case "SubState":
+			status.SubState = value
+		case "MainPID":
+			status.MainPID = value
+		case "UnitFileState":
+			status.Enabled = value
+		}
+	}
+	return status, nil
+}
+
+// Find returns the installed unit of the project, preferring a user unit,
+// or nil when the project has none.
+func Find(projectPath string) *Unit {
+	for _, scope := range []Scope{User, System} {
+		if u := NewUnit(projectPath, scope); u.Installed() {
+			return u
+		}
+	}
+	return nil
+}
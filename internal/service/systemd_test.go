@@ -0,0 +1,74 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnitName(t *testing.T) {
+	a := UnitName("/srv/sites/my site")
+	b := UnitName("/home/me/my site")
+
+	if !strings.HasPrefix(a, "umono-my-site-") || !strings.HasSuffix(a, ".service") {
+		t.Errorf("UnitName() = %q", a)
+	}
+	if a == b {
+		t.Errorf("projects with the same directory name got the same unit %q", a)
+	}
+	if a != UnitName("/srv/sites/my site") {
+		t.Error("UnitName() is not stable")
+	}
+}
+
+func TestRender(t *testing.T) {
+	u := NewUnit("/srv/my site", System)
+	u.RunAs = "www"
+	unit := u.Render()
+
+	for _, want := range []string{
+		"User=www\n",
+		"WorkingDirectory=/srv/my site\n",
+		`ExecStart="/srv/my site/umono"` + "\n",
+		"Restart=on-failure\n",
+		"StandardOutput=journal\n",
+		"WantedBy=multi-user.target\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("system unit does not contain %q:\n%s", want, unit)
+		}
+	}
+
+	if strings.Contains(unit, "EnvironmentFile=") {
+		t.Errorf("unit passes .env to systemd, which cannot parse dotenv syntax:\n%s", unit)
+	}
+
+	userUnit := NewUnit("/home/me/site", User).Render()
+	if strings.Contains(userUnit, "User=") || !strings.Contains(userUnit, "WantedBy=default.target") {
+		t.Errorf("unexpected user unit:\n%s", userUnit)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	var calls [][]string
+	original := runSystemctl
+	defer func() { runSystemctl = original }()
+	runSystemctl = func(args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		return []byte("ActiveState=active\nSubState=running\nMainPID=4242\nUnitFileState=enabled\n"), nil
+	}
+
+	u := NewUnit("/home/me/site", User)
+	status, err := u.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	want := &Status{ActiveState: "active", SubState: "running", MainPID: "4242", Enabled: "enabled"}
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("Status() = %+v, want %+v", status, want)
+	}
+	if len(calls) != 1 || calls[0][0] != "--user" {
+		t.Errorf("systemctl called with %v, want --user first", calls)
+	}
+}
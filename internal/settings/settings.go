@@ -0,0 +1,103 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const DefaultPort = "8999"
+
+// credentialKeys hold the bcrypt hashes of the root account and are only
+// written by the commands that hash them.
+var credentialKeys = map[string]bool{
+	"HASHED_USERNAME": true,
+	"HASHED_PASSWORD": true,
+}
+
+// requiredKeys cannot be removed without breaking the server.
+var requiredKeys = map[string]bool{
+	"PORT":            true,
+	"HASHED_USERNAME": true,
+	"HASHED_PASSWORD": true,
+}
+
+var validators = map[string]func(string) error{
+	"PORT":    ValidatePort,
+	"APP_ENV": validateAppEnv,
+	"DSN":     validateNotEmpty,
+}
+
+func ValidatePort(port string) error {
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
+		return errors.New("port must be a number")
+	}
+
+	if portNum < 1 || portNum > 65535 {
+		return errors.New("port must be between 1 and 65535")
+	}
+
+	if portNum < 1024 {
+		return errors.New("ports below 1024 require root privileges")
+	}
+
+	return nil
+}
+
+func validateAppEnv(value string) error {
+	if value != "dev" && value != "prod" {
+		return errors.New("must be dev or prod")
+	}
+	return nil
+}
+
+func validateNotEmpty(value string) error {
+	if value == "" {
+		return errors.New("must not be empty")
+	}
+	return nil
+}
+
+// Validate checks a value for a known key. Unknown keys are accepted as is.
+func Validate(key, value string) error {
+	if credentialKeys[key] {
+		return fmt.Errorf("%s is a credential hash; use 'umono passwd' to change it", key)
+	}
+
+	if validate, ok := validators[key]; ok {
+		if err := validate(value); err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func IsRequired(key string) bool {
+	return requiredKeys[key]
+}
+
+// IsSecret reports whether the value of key should be hidden when printed.
+func IsSecret(key string) bool {
+	if credentialKeys[key] {
+		return true
+	}
+
+	for _, marker := range []string{"PASSWORD", "SECRET", "TOKEN", "PRIVATE_KEY"} {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Mask hides a secret value without revealing its length.
+func Mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "********"
+}
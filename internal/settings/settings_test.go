@@ -0,0 +1,48 @@
+package settings
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"PORT", "8999", false},
+		{"PORT", "abc", true},
+		{"PORT", "0", true},
+		{"PORT", "70000", true},
+		{"PORT", "80", true},
+		{"APP_ENV", "prod", false},
+		{"APP_ENV", "staging", true},
+		{"DSN", "", true},
+		{"DSN", "umono.db", false},
+		{"HASHED_PASSWORD", "anything", true},
+		{"CUSTOM_KEY", "anything goes", false},
+	}
+
+	for _, tt := range tests {
+		err := Validate(tt.key, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q, %q) error = %v, wantErr %v", tt.key, tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestIsSecret(t *testing.T) {
+	tests := map[string]bool{
+		"HASHED_USERNAME": true,
+		"HASHED_PASSWORD": true,
+		"PASSWORD":        true,
+		"SMTP_PASSWORD":   true,
+		"API_TOKEN":       true,
+		"PORT":            false,
+		"USERNAME":        false,
+	}
+
+	for key, want := range tests {
+		if got := IsSecret(key); got != want {
+			t.Errorf("IsSecret(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package signature
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ReleaseKey is the minisign public key Umono releases are signed with. It is
+// set at build time:
+//
+//	go build -ldflags "-X github.com/umono-cms/cli/internal/signature.ReleaseKey=RW..."
+var ReleaseKey string
+
+var (
+	ErrUnknownKey       = errors.New("signature was made with an untrusted key")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+const (
+	algLegacy    = "Ed"
+	algPrehashed = "ED"
+
+	trustedCommentPrefix = "trusted comment: "
+)
+
+type PublicKey struct {
+	KeyID [8]byte
+	Key   ed25519.PublicKey
+}
+
+func (pk *PublicKey) ID() string {
+	return strings.ToUpper(hex.EncodeToString(reverse(pk.KeyID[:])))
+}
+
+type Signature struct {
+	Algorithm       string
+	KeyID           [8]byte
+	Signature       []byte
+	TrustedComment  string
+	GlobalSignature []byte
+}
+
+// ParsePublicKey accepts either the bare base64 key or the full contents of a
+// minisign .pub file.
+func ParsePublicKey(s string) (*PublicKey, error) {
+	encoded := ""
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "untrusted comment:") {
+			continue
+		}
+		encoded = line
+		break
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key encoding: %w", err)
+	}
+
+	if len(raw) != 2+8+ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key length: %d", len(raw))
+	}
+
+	if string(raw[:2]) != algLegacy {
+		return nil, fmt.Errorf("unsupported public key algorithm: %q", raw[:2])
+	}
+
+	pk := &PublicKey{Key: ed25519.PublicKey(raw[10:])}
+	copy(pk.KeyID[:], raw[2:10])
+
+	return pk, nil
+}
+
+func ParseSignature(data []byte) (*Signature, error) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "untrusted comment:") || !strings.HasPrefix(lines[2], trustedCommentPrefix) {
+		return nil, fmt.Errorf("malformed minisign signature")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	if len(raw) != 2+8+ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid signature length: %d", len(raw))
+	}
+
+	globalSig, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid global signature encoding: %w", err)
+	}
+	if len(globalSig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid global signature length: %d", len(globalSig))
+	}
+
+	sig := &Signature{
+		Algorithm:       string(raw[:2]),
+		Signature:       raw[10:],
+		TrustedComment:  strings.TrimPrefix(lines[2], trustedCommentPrefix),
+		GlobalSignature: globalSig,
+	}
+	copy(sig.KeyID[:], raw[2:10])
+
+	if sig.Algorithm != algLegacy && sig.Algorithm != algPrehashed {
+		return nil, fmt.Errorf("unsupported signature algorithm: %q", sig.Algorithm)
+	}
+
+	return sig, nil
+}
+
+func (pk *PublicKey) Verify(message []byte, sig *Signature) error {
+	if pk.KeyID != sig.KeyID {
+		return ErrUnknownKey
+	}
+
+	signed := message
+	if sig.Algorithm == algPrehashed {
+		sum := blake2b.Sum512(message)
+		signed = sum[:]
+	}
+
+	if !ed25519.Verify(pk.Key, signed, sig.Signature) {
+		return ErrInvalidSignature
+	}
+
+	global := append(append([]byte{}, sig.Signature...), sig.TrustedComment...)
+	if !ed25519.Verify(pk.Key, global, sig.GlobalSignature) {
+		return fmt.Errorf("%w: trusted comment does not match", ErrInvalidSignature)
+	}
+
+	return nil
+}
+
+// Verify checks message against a minisign signature made by any of keys and
+// returns the key that produced it.
+func Verify(message, sigData []byte, keys []*PublicKey) (*PublicKey, error) {
+	sig, err := ParseSignature(sigData)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if key.KeyID == sig.KeyID {
+			return key, key.Verify(message, sig)
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
+
+func reverse(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+	return out
+}
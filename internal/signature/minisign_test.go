@@ -0,0 +1,150 @@
+package signature
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+type testKey struct {
+	id      [8]byte
+	public  ed25519.PublicKey
+	private ed25519.PrivateKey
+}
+
+func newTestKey(t *testing.T) *testKey {
+	t.Helper()
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	key := &testKey{public: public, private: private}
+	if _, err := rand.Read(key.id[:]); err != nil {
+		t.Fatalf("failed to generate key id: %v", err)
+	}
+
+	return key
+}
+
+func (k *testKey) publicKeyFile() string {
+	raw := append([]byte(algLegacy), k.id[:]...)
+	raw = append(raw, k.public...)
+	return "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(raw) + "\n"
+}
+
+func (k *testKey) sign(message []byte, algorithm, trustedComment string) []byte {
+	signed := message
+	if algorithm == algPrehashed {
+		sum := blake2b.Sum512(message)
+		signed = sum[:]
+	}
+
+	sig := ed25519.Sign(k.private, signed)
+	raw := append([]byte(algorithm), k.id[:]...)
+	raw = append(raw, sig...)
+
+	global := ed25519.Sign(k.private, append(append([]byte{}, sig...), trustedComment...))
+
+	return []byte(fmt.Sprintf("untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(raw), trustedComment, base64.StdEncoding.EncodeToString(global)))
+}
+
+func TestParsePublicKey(t *testing.T) {
+	key := newTestKey(t)
+
+	pk, err := ParsePublicKey(key.publicKeyFile())
+	if err != nil {
+		t.Fatalf("ParsePublicKey() unexpected error: %v", err)
+	}
+	if pk.KeyID != key.id {
+		t.Errorf("ParsePublicKey() key id = %x, want %x", pk.KeyID, key.id)
+	}
+	if !pk.Key.Equal(key.public) {
+		t.Error("ParsePublicKey() returned wrong key")
+	}
+
+	if _, err := ParsePublicKey("not base64!"); err == nil {
+		t.Error("ParsePublicKey() expected error for invalid encoding")
+	}
+	if _, err := ParsePublicKey(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Error("ParsePublicKey() expected error for invalid length")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	message := []byte("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  umono.tar.gz\n")
+
+	trusted := newTestKey(t)
+	untrusted := newTestKey(t)
+
+	trustedPK, err := ParsePublicKey(trusted.publicKeyFile())
+	if err != nil {
+		t.Fatalf("ParsePublicKey() unexpected error: %v", err)
+	}
+	keys := []*PublicKey{trustedPK}
+
+	tests := []struct {
+		name    string
+		sig     []byte
+		message []byte
+		wantErr error
+	}{
+		{"prehashed signature", trusted.sign(message, algPrehashed, "timestamp:1"), message, nil},
+		{"legacy signature", trusted.sign(message, algLegacy, "timestamp:1"), message, nil},
+		{"tampered message", trusted.sign(message, algPrehashed, "timestamp:1"), []byte("tampered"), ErrInvalidSignature},
+		{"untrusted key", untrusted.sign(message, algPrehashed, "timestamp:1"), message, ErrUnknownKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := Verify(tt.message, tt.sig, keys)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && key != trustedPK {
+				t.Error("Verify() returned wrong key")
+			}
+		})
+	}
+}
+
+func TestVerify_TamperedTrustedComment(t *testing.T) {
+	message := []byte("checksums")
+	key := newTestKey(t)
+
+	pk, err := ParsePublicKey(key.publicKeyFile())
+	if err != nil {
+		t.Fatalf("ParsePublicKey() unexpected error: %v", err)
+	}
+
+	sig, err := ParseSignature(key.sign(message, algPrehashed, "timestamp:1"))
+	if err != nil {
+		t.Fatalf("ParseSignature() unexpected error: %v", err)
+	}
+	sig.TrustedComment = "timestamp:2"
+
+	if err := pk.Verify(message, sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestParseSignature_Malformed(t *testing.T) {
+	inputs := []string{
+		"",
+		"untrusted comment: x\nAAAA\n",
+		"untrusted comment: x\nnot base64\ntrusted comment: y\nAAAA\n",
+	}
+
+	for _, input := range inputs {
+		if _, err := ParseSignature([]byte(input)); err == nil {
+			t.Errorf("ParseSignature(%q) expected error", input)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+type processInfo struct {
+	startTime  string
+	executable string
+}
+
+// inspect reads the start time and executable of pid from /proc where it
+// exists, and from ps(1) elsewhere.
+func inspect(pid int) (processInfo, error) {
+	if _, err := os.Stat("/proc/self/stat"); err == nil {
+		return inspectProc(pid)
+	}
+	return inspectPS(pid)
+}
+
+func inspectProc(pid int) (processInfo, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return processInfo{}, err
+	}
+
+	startTime, err := parseStatStartTime(string(data))
+	if err != nil {
+		return processInfo{}, err
+	}
+
+	exe, _ := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	return processInfo{
+		startTime:  startTime,
+		executable: strings.TrimSuffix(exe, " (deleted)"),
+	}, nil
+}
+
+// bootID identifies the current boot on Linux. Elsewhere start times come
+// from ps(1) as wall-clock dates, which do not repeat across reboots, and it
+// returns "".
+func bootID() string {
+	data, err := os.ReadFile("/proc/sys/kernel/random/boot_id")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// parseStatStartTime returns field 22 of /proc/<pid>/stat, the start time in
+// clock ticks since boot. The command name in field 2 may contain spaces and
+// parentheses, so fields are counted from the last ')'.
+func parseStatStartTime(stat string) (string, error) {
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return "", fmt.Errorf("malformed stat line")
+	}
+
+	// Fields after the command start at field 3.
+	fields := strings.Fields(stat[end+1:])
+	const startTimeField = 22 - 3
+	if len(fields) <= startTimeField {
+		return "", fmt.Errorf("malformed stat line")
+	}
+
+	if _, err := strconv.ParseUint(fields[startTimeField], 10, 64); err != nil {
+		return "", fmt.Errorf("malformed start time %q", fields[startTimeField])
+	}
+	return fields[startTimeField], nil
+}
+
+func inspectPS(pid int) (processInfo, error) {
+	out, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return processInfo{}, err
+	}
+	startTime := strings.TrimSpace(string(out))
+	if startTime == "" {
+		return processInfo{}, fmt.Errorf("process %d not found", pid)
+	}
+
+	exe, _ := exec.Command("ps", "-o", "comm=", "-p", strconv.Itoa(pid)).Output()
+	return processInfo{
+		startTime:  startTime,
+		executable: strings.TrimSpace(string(exe)),
+	}, nil
+}
@@ -0,0 +1,267 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	Dir      = ".umono"
+	FileName = "state.json"
+	lockName = "state.lock"
+	// legacyPIDFile is where older CLI versions recorded the PID.
+	legacyPIDFile = ".PID"
+)
+
+type Mode string
+
+const (
+	Detached   Mode = "detached"
+	Foreground Mode = "foreground"
+)
+
+// State identifies the process running a project's Umono. The boot and start
+// time, or where there is no boot ID the start time and executable, tell a
+// live instance apart from an unrelated process that was given the same PID
+// later, e.g. after a reboot.
+type State struct {
+	PID int `json:"pid"`
+	// BootID identifies the boot the process was started in. Start times
+	// in /proc count from boot, so they can repeat across reboots.
+	BootID     string    `json:"boot_id,omitempty"`
+	StartTime  string    `json:"start_time,omitempty"`
+	Executable string    `json:"executable,omitempty"`
+	Mode       Mode      `json:"mode"`
+	StartedAt  time.Time `json:"started_at"`
+
+	// Legacy is set for a PID read from an old .PID file, which records
+	// nothing but the PID.
+	Legacy bool `json:"-"`
+
+	projectPath string
+}
+
+// New describes the running process pid.
+func New(pid int, mode Mode) *State {
+	s := &State{PID: pid, BootID: bootID(), Mode: mode, StartedAt: time.Now().UTC()}
+	if info, err := inspect(pid); err == nil {
+		s.StartTime = info.startTime
+		s.Executable = info.executable
+	}
+	return s
+}
+
+// Alive reports whether the recorded process is still the one that was
+// started for the project.
+func (s *State) Alive() bool {
+	if s.PID <= 0 || syscall.Kill(s.PID, 0) != nil {
+		return false
+	}
+
+	if s.Legacy {
+		return s.legacyMatches()
+	}
+
+	if s.BootID != "" && s.BootID != bootID() {
+		return false
+	}
+
+	if s.StartTime == "" {
+		return true
+	}
+
+	info, err := inspect(s.PID)
+	if err != nil {
+		// The process exists but cannot be inspected; trust the PID.
+		return true
+	}
+
+	if info.startTime != s.StartTime {
+		return false
+	}
+	if s.BootID != "" {
+		// Boot and start time identify the process on their own. Its
+		// executable path is no help here: an upgrade renames the running
+		// binary to umono.backup and deletes it.
+		return true
+	}
+	// Without a boot ID the start time comes from ps(1) and only has second
+	// resolution, so the executable it was started from is compared too.
+	return s.Executable == "" || info.executable == "" || info.executable == s.Executable
+}
+
+// legacyMatches checks the executable of a PID from an old .PID file against
+// what older CLIs ran there: the project's umono binary itself, or the CLI,
+// installed as umono, supervising it.
+func (s *State) legacyMatches() bool {
+	info, err := inspect(s.PID)
+	if err != nil || info.executable == "" {
+		return false
+	}
+
+	binary := filepath.Join(s.projectPath, "umono")
+	switch info.executable {
+	case binary, binary + ".backup":
+		return true
+	}
+	return filepath.Base(info.executable) == "umono"
+}
+
+// Handle is an open, locked state file. Hold an exclusive handle around
+// checking for a running instance and recording a new one so that two
+// commands cannot start the project at the same time.
+type Handle struct {
+	dir  string
+	lock *os.File
+}
+
+func Path(projectPath string) string {
+	return filepath.Join(projectPath, Dir, FileName)
+}
+
+// Open locks the project's state, shared or exclusive, and blocks until the
+// lock is available.
+func Open(projectPath string, exclusive bool) (*Handle, error) {
+	if err := os.MkdirAll(filepath.Join(projectPath, Dir), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", Dir, err)
+	}
+
+	lock, err := os.OpenFile(filepath.Join(projectPath, Dir, lockName), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state lock: %w", err)
+	}
+
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	if err := syscall.Flock(int(lock.Fd()), how); err != nil {
+		lock.Close()
+		return nil, fmt.Errorf("failed to lock state: %w", err)
+	}
+
+	return &Handle{dir: projectPath, lock: lock}, nil
+}
+
+// Read returns the recorded state, falling back to a legacy .PID file. It
+// returns an error satisfying os.IsNotExist when nothing is recorded.
+func (h *Handle) Read() (*State, error) {
+	data, err := os.ReadFile(Path(h.dir))
+	if os.IsNotExist(err) {
+		return readLegacy(h.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	return &s, nil
+}
+
+func readLegacy(projectPath string) (*State, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, legacyPIDFile))
+	if err != nil {
+		return nil, err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PID in %s: %q", legacyPIDFile, strings.TrimSpace(string(data)))
+	}
+
+	return &State{PID: pid, Legacy: true, projectPath: projectPath}, nil
+}
+
+func (h *Handle) Write(s *State) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := Path(h.dir) + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := os.Rename(tmp, Path(h.dir)); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+
+	os.Remove(filepath.Join(h.dir, legacyPIDFile))
+	return nil
+}
+
+func (h *Handle) Remove() error {
+	err := os.Remove(Path(h.dir))
+	if legacyErr := os.Remove(filepath.Join(h.dir, legacyPIDFile)); err == nil || os.IsNotExist(err) {
+		err = legacyErr
+	}
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (h *Handle) Close() error {
+	return h.lock.Close()
+}
+
+// Load reads the recorded state under a shared lock.
+func Load(projectPath string) (*State, error) {
+	h, err := Open(projectPath, false)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+
+	return h.Read()
+}
+
+// Running returns the state of the project's live instance, or nil when none
+// is running.
+func Running(projectPath string) (*State, error) {
+	s, err := Load(projectPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !s.Alive() {
+		// An old .PID cannot be told apart from a reused PID later on, so it
+		// is dropped as soon as it is found to be stale.
+		if s.Legacy {
+			RemoveIf(projectPath, s.PID)
+		}
+		return nil, nil
+	}
+	return s, nil
+}
+
+// RemoveIf removes the state only while it still records pid, so an exiting
+// process never removes the record of its replacement.
+func RemoveIf(projectPath string, pid int) error {
+	h, err := Open(projectPath, true)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	s, err := h.Read()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil || s.PID != pid {
+		return err
+	}
+	return h.Remove()
+}
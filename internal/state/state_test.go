@@ -0,0 +1,235 @@
+package state
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestParseStatStartTime(t *testing.T) {
+	stat := "1234 (my (odd) cmd) S 1 1234 1234 0 -1 4194560 100 0 0 0 1 2 0 0 20 0 1 0 98765 1000000 200 18446744073709551615\n"
+
+	got, err := parseStatStartTime(stat)
+	if err != nil {
+		t.Fatalf("parseStatStartTime() error = %v", err)
+	}
+	if got != "98765" {
+		t.Errorf("parseStatStartTime() = %q, want %q", got, "98765")
+	}
+
+	if _, err := parseStatStartTime("garbage"); err == nil {
+		t.Error("parseStatStartTime(garbage) expected error")
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Load(dir); !os.IsNotExist(err) {
+		t.Fatalf("Load() on empty project error = %v, want not exist", err)
+	}
+
+	h, err := Open(dir, true)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := h.Write(New(os.Getpid(), Foreground)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	h.Close()
+
+	running, err := Running(dir)
+	if err != nil || running == nil {
+		t.Fatalf("Running() = %v, %v; want this process", running, err)
+	}
+	if running.PID != os.Getpid() || running.Mode != Foreground {
+		t.Errorf("Running() = %+v", running)
+	}
+
+	if err := RemoveIf(dir, os.Getpid()+1); err != nil {
+		t.Fatalf("RemoveIf() error = %v", err)
+	}
+	if _, err := Load(dir); err != nil {
+		t.Errorf("RemoveIf() with another PID removed the state")
+	}
+
+	if err := RemoveIf(dir, os.Getpid()); err != nil {
+		t.Fatalf("RemoveIf() error = %v", err)
+	}
+	if _, err := Load(dir); !os.IsNotExist(err) {
+		t.Errorf("state still present after RemoveIf()")
+	}
+}
+
+func TestAlive_ReusedPID(t *testing.T) {
+	s := New(os.Getpid(), Detached)
+	if s.StartTime == "" {
+		t.Skip("process start time is not available")
+	}
+
+	if !s.Alive() {
+		t.Fatal("Alive() = false for this process")
+	}
+
+	tests := []struct {
+		name     string
+		recorded bool
+		change   func(*State)
+	}{
+		{"another start time", true, func(s *State) { s.StartTime = "1" }},
+		{"another boot", s.BootID != "", func(s *State) { s.BootID = "00000000-0000-0000-0000-000000000000" }},
+		{"another executable", s.Executable != "", func(s *State) {
+			s.BootID = ""
+			s.Executable = "/usr/sbin/unrelated"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.recorded {
+				t.Skip("not recorded on this platform")
+			}
+			reused := *s
+			tt.change(&reused)
+			if reused.Alive() {
+				t.Errorf("Alive() = true for a PID with %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestAlive_UpgradedBinary(t *testing.T) {
+	s := New(os.Getpid(), Foreground)
+	if s.StartTime == "" || s.BootID == "" {
+		t.Skip("boot ID or process start time is not available")
+	}
+
+	// An upgrade renames the running binary away and deletes it.
+	s.Executable = "/srv/site/umono"
+	if !s.Alive() {
+		t.Error("Alive() = false for a process whose binary was replaced")
+	}
+}
+
+func TestAlive_ExitedProcess(t *testing.T) {
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skip("true is not available")
+	}
+
+	if (&State{PID: cmd.Process.Pid, StartTime: "1"}).Alive() {
+		t.Error("Alive() = true for an exited process")
+	}
+}
+
+func TestLegacyPIDFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".PID"), []byte("4242\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if s.PID != 4242 || !s.Legacy {
+		t.Errorf("Load() = %+v, want legacy PID 4242", s)
+	}
+
+	h, _ := Open(dir, true)
+	h.Write(New(os.Getpid(), Foreground))
+	h.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, ".PID")); !os.IsNotExist(err) {
+		t.Error("legacy .PID was not removed when the state was written")
+	}
+}
+
+func TestRunning_CorruptState(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+	}{
+		{"invalid state file", filepath.Join(Dir, FileName), "{bad"},
+		{"invalid legacy PID", ".PID", "abc\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, tt.file)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := Running(dir)
+			if err == nil || os.IsNotExist(err) {
+				t.Fatalf("Running() = %+v, %v; want a parse error", s, err)
+			}
+			if s != nil {
+				t.Errorf("Running() state = %+v, want nil with an error", s)
+			}
+		})
+	}
+}
+
+func TestRunning_LegacyPID(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not available")
+	}
+	data, err := os.ReadFile(sleep)
+	if err != nil {
+		t.Skip("cannot read sleep")
+	}
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "umono")
+	if err := os.WriteFile(binary, data, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	server := exec.Command(binary, "10")
+	if err := server.Start(); err != nil {
+		t.Skipf("cannot run a copy of sleep: %v", err)
+	}
+	defer func() {
+		server.Process.Kill()
+		server.Wait()
+	}()
+
+	tests := []struct {
+		name string
+		pid  int
+		want bool
+	}{
+		{"the project's umono", server.Process.Pid, true},
+		{"an unrelated process", os.Getpid(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pidFile := filepath.Join(dir, ".PID")
+			if err := os.WriteFile(pidFile, []byte(strconv.Itoa(tt.pid)), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := Running(dir)
+			if err != nil {
+				t.Fatalf("Running() error = %v", err)
+			}
+			if (s != nil) != tt.want {
+				t.Fatalf("Running() = %+v, want running %v", s, tt.want)
+			}
+			if _, err := os.Stat(pidFile); tt.want == os.IsNotExist(err) {
+				t.Errorf(".PID kept = %v, want %v", !os.IsNotExist(err), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StatusFile is the name of the file, inside the project's metadata
+// directory, where the supervisor records what happened to the server.
+const StatusFile = "supervisor.json"
+
+type Policy string
+
+const (
+	RestartNever     Policy = "no"
+	RestartOnFailure Policy = "on-failure"
+	RestartAlways    Policy = "always"
+)
+
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case RestartNever, RestartOnFailure, RestartAlways:
+		return p, nil
+	case "":
+		return RestartNever, nil
+	}
+	return "", fmt.Errorf("invalid restart policy %q: use no, on-failure or always", s)
+}
+
+// Status is written by the supervisor whenever the server starts or exits.
+type Status struct {
+	PID         int       `json:"pid"`
+	ServerPID   int       `json:"server_pid,omitempty"`
+	Policy      Policy    `json:"policy"`
+	MaxRestarts int       `json:"max_restarts,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	Restarts    int       `json:"restarts"`
+
+	// LastExitCode is -1 when the server was killed by a signal.
+	LastExitCode *int      `json:"last_exit_code,omitempty"`
+	LastExit     string    `json:"last_exit,omitempty"`
+	LastExitAt   time.Time `json:"last_exit_at,omitempty"`
+}
+
+func LoadStatus(path string) (*Status, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Status
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse supervisor status: %w", err)
+	}
+	return &s, nil
+}
+
+func (s *Status) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
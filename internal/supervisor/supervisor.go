@@ -0,0 +1,252 @@
+package supervisor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/umono-cms/cli/internal/logfile"
+)
+
+var (
+	initialBackoff = time.Second
+	maxBackoff     = time.Minute
+	// stableRun is how long the server has to stay up for the backoff and
+	// the restart limit to start over.
+	stableRun = time.Minute
+	// outputGrace is how long output is still collected after the server
+	// exits. Processes it left behind may keep the pipe open indefinitely.
+	outputGrace = 2 * time.Second
+)
+
+// Options describe the server a supervisor runs on behalf of a detached
+// `umono up`.
+type Options struct {
+	Dir    string
+	Binary string
+	Log    logfile.Options
+
+	Restart Policy
+	// MaxRestarts limits restarts in a row without a stable run. Zero means
+	// no limit.
+	MaxRestarts int
+	// StatusPath is where the Status is recorded; empty disables it.
+	StatusPath string
+}
+
+type supervisor struct {
+	opts    Options
+	log     *logfile.Writer
+	status  Status
+	signals chan os.Signal
+	// stopping is set once the supervisor was asked to stop.
+	stopping bool
+}
+
+// Run starts the server and copies its output into the rotating log until
+// it exits, restarting it according to the restart policy. SIGTERM and
+// SIGINT are forwarded to the server and stop the supervisor; SIGHUP rotates
+// the log. The returned error is an *exec.ExitError when the server failed.
+func Run(opts Options) error {
+	log, err := logfile.Open(opts.Log)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	if opts.Restart == "" {
+		opts.Restart = RestartNever
+	}
+
+	s := &supervisor{
+		opts: opts,
+		log:  log,
+		status: Status{
+			PID:         os.Getpid(),
+			Policy:      opts.Restart,
+			MaxRestarts: opts.MaxRestarts,
+			StartedAt:   time.Now().UTC(),
+		},
+		signals: make(chan os.Signal, 1),
+	}
+
+	signal.Notify(s.signals, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+	defer signal.Stop(s.signals)
+
+	backoff := initialBackoff
+	failures := 0
+	for {
+		started := time.Now()
+		err := s.runOnce()
+		if s.stopping || !s.shouldRestart(err) {
+			return err
+		}
+
+		if time.Since(started) >= stableRun {
+			backoff = initialBackoff
+			failures = 0
+		}
+
+		failures++
+		if s.opts.MaxRestarts > 0 && failures > s.opts.MaxRestarts {
+			s.logEvent("giving up after %d restarts", s.opts.MaxRestarts)
+			return err
+		}
+
+		s.logEvent("restarting server in %s", backoff)
+		if !s.sleep(backoff) {
+			return err
+		}
+		backoff = min(backoff*2, maxBackoff)
+
+		s.status.Restarts++
+	}
+}
+
+func (s *supervisor) shouldRestart(err error) bool {
+	switch s.opts.Restart {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return err != nil
+	}
+	return false
+}
+
+// sleep waits for d while still handling signals. It returns false when the
+// supervisor was asked to stop.
+func (s *supervisor) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return true
+		case sig := <-s.signals:
+			if sig == syscall.SIGHUP {
+				s.rotate()
+				continue
+			}
+			s.stopping = true
+			return false
+		}
+	}
+}
+
+func (s *supervisor) runOnce() error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to create output pipe: %w", err)
+	}
+
+	server := exec.Command(s.opts.Binary)
+	server.Dir = s.opts.Dir
+	server.Stdout = w
+	server.Stderr = w
+
+	if err := server.Start(); err != nil {
+		r.Close()
+		w.Close()
+		s.logEvent("failed to start server: %v", err)
+		s.recordExit(-1, err.Error())
+		return err
+	}
+	// The server and its children hold the only write ends now, so the copy
+	// below ends once they have all exited.
+	w.Close()
+
+	s.status.ServerPID = server.Process.Pid
+	s.saveStatus()
+
+	copied := make(chan struct{})
+	go func() {
+		copyLines(s.log, r)
+		r.Close()
+		close(copied)
+	}()
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- server.Wait()
+	}()
+
+	for {
+		select {
+		case sig := <-s.signals:
+			if sig == syscall.SIGHUP {
+				s.rotate()
+				continue
+			}
+			s.stopping = true
+			server.Process.Signal(sig)
+		case err := <-exited:
+			select {
+			case <-copied:
+			case <-time.After(outputGrace):
+				r.Close()
+				<-copied
+			}
+			s.status.ServerPID = 0
+
+			var exitErr *exec.ExitError
+			switch {
+			case errors.As(err, &exitErr):
+				s.logEvent("server exited with %v", exitErr)
+				s.recordExit(exitErr.ExitCode(), exitErr.Error())
+			case err != nil:
+				s.logEvent("server failed: %v", err)
+				s.recordExit(-1, err.Error())
+			default:
+				s.recordExit(0, "exit status 0")
+			}
+			return err
+		}
+	}
+}
+
+func (s *supervisor) rotate() {
+	if err := s.log.Rotate(); err != nil {
+		s.logEvent("failed to rotate log: %v", err)
+	}
+}
+
+func (s *supervisor) recordExit(code int, reason string) {
+	s.status.LastExitCode = &code
+	s.status.LastExit = reason
+	s.status.LastExitAt = time.Now().UTC()
+	s.saveStatus()
+}
+
+func (s *supervisor) saveStatus() {
+	if s.opts.StatusPath == "" {
+		return
+	}
+	if err := s.status.Save(s.opts.StatusPath); err != nil {
+		s.logEvent("failed to record status: %v", err)
+	}
+}
+
+func (s *supervisor) logEvent(format string, args ...any) {
+	fmt.Fprintf(s.log, "%s umono-cli: %s\n", time.Now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+}
+
+// copyLines writes whole lines so that rotation never splits one.
+func copyLines(dst io.Writer, src io.Reader) {
+	reader := bufio.NewReader(src)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			dst.Write(line)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package supervisor
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/umono-cms/cli/internal/logfile"
+)
+
+func writeScript(t *testing.T, dir, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, "umono")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun_CapturesOutput(t *testing.T) {
+	dir := t.TempDir()
+	binary := writeScript(t, dir, "echo out\necho err >&2\nexit 3\n")
+	logPath := filepath.Join(dir, "logs", "umono.log")
+
+	err := Run(Options{Dir: dir, Binary: binary, Log: logfile.Options{Path: logPath}})
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("Run() error = %v, want exit status 3", err)
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	log := string(data)
+	for _, want := range []string{"out\n", "err\n", "server exited with exit status 3"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("log %q does not contain %q", log, want)
+		}
+	}
+}
+
+func TestRun_RestartsOnFailure(t *testing.T) {
+	initialBackoff = 10 * time.Millisecond
+	defer func() { initialBackoff = time.Second }()
+
+	dir := t.TempDir()
+	binary := writeScript(t, dir, "echo run >> runs\nexit 1\n")
+	statusPath := filepath.Join(dir, ".umono", StatusFile)
+
+	err := Run(Options{
+		Dir:         dir,
+		Binary:      binary,
+		Log:         logfile.Options{Path: filepath.Join(dir, "umono.log")},
+		Restart:     RestartOnFailure,
+		MaxRestarts: 2,
+		StatusPath:  statusPath,
+	})
+	if err == nil {
+		t.Fatal("Run() expected the last failure to be returned")
+	}
+
+	runs, _ := os.ReadFile(filepath.Join(dir, "runs"))
+	if got := strings.Count(string(runs), "run"); got != 3 {
+		t.Errorf("server ran %d times, want 3", got)
+	}
+
+	status, err := LoadStatus(statusPath)
+	if err != nil {
+		t.Fatalf("LoadStatus() error = %v", err)
+	}
+	if status.Restarts != 2 {
+		t.Errorf("Restarts = %d, want 2", status.Restarts)
+	}
+	if status.LastExitCode == nil || *status.LastExitCode != 1 {
+		t.Errorf("LastExitCode = %v, want 1", status.LastExitCode)
+	}
+}
+
+func TestRun_OnFailureStopsOnCleanExit(t *testing.T) {
+	dir := t.TempDir()
+	binary := writeScript(t, dir, "echo run >> runs\nexit 0\n")
+
+	err := Run(Options{
+		Dir:     dir,
+		Binary:  binary,
+		Log:     logfile.Options{Path: filepath.Join(dir, "umono.log")},
+		Restart: RestartOnFailure,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	runs, _ := os.ReadFile(filepath.Join(dir, "runs"))
+	if got := strings.Count(string(runs), "run"); got != 1 {
+		t.Errorf("server ran %d times, want 1", got)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for _, s := range []string{"", "no", "on-failure", "always"} {
+		if _, err := ParsePolicy(s); err != nil {
+			t.Errorf("ParsePolicy(%q) error = %v", s, err)
+		}
+	}
+	if _, err := ParsePolicy("sometimes"); err == nil {
+		t.Error("ParsePolicy(sometimes) expected error")
+	}
+}
+
+func TestRun_DoesNotWaitForLeftoverChildren(t *testing.T) {
+	outputGrace = 100 * time.Millisecond
+	defer func() { outputGrace = 2 * time.Second }()
+
+	dir := t.TempDir()
+	binary := writeScript(t, dir, "sleep 5 &\necho started\nexit 0\n")
+
+	start := time.Now()
+	if err := Run(Options{Dir: dir, Binary: binary, Log: logfile.Options{Path: filepath.Join(dir, "umono.log")}}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Run() took %s, want it to return shortly after the server exited", elapsed)
+	}
+}
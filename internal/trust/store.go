@@ -0,0 +1,107 @@
+package trust
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Store pins the SHA-256 of every release asset the CLI has installed, per
+// release tag, so a tag that is later re-published with different assets is
+// noticed (trust on first use).
+type Store struct {
+	path string
+
+	// Releases maps a release tag to asset names and their pinned SHA-256.
+	Releases map[string]map[string]string `json:"releases"`
+}
+
+type MismatchError struct {
+	Tag      string
+	Asset    string
+	Pinned   string
+	Received string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("%s from release %s changed since it was first installed: pinned %s, got %s", e.Asset, e.Tag, e.Pinned, e.Received)
+}
+
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "umono", "known_releases.json"), nil
+}
+
+func Load(path string) (*Store, error) {
+	s := &Store{
+		path:     path,
+		Releases: make(map[string]map[string]string),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trust store: %w", err)
+	}
+
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse trust store %s: %w", path, err)
+	}
+
+	if s.Releases == nil {
+		s.Releases = make(map[string]map[string]string)
+	}
+
+	return s, nil
+}
+
+// Check returns a *MismatchError when a different hash is already pinned for
+// the asset. Assets seen for the first time pass.
+func (s *Store) Check(tag, asset, hash string) error {
+	pinned, ok := s.Releases[tag][asset]
+	if !ok || pinned == hash {
+		return nil
+	}
+
+	return &MismatchError{
+		Tag:      tag,
+		Asset:    asset,
+		Pinned:   pinned,
+		Received: hash,
+	}
+}
+
+// Record pins hash for the asset unless it is already pinned. An existing
+// pin is never replaced.
+func (s *Store) Record(tag, asset, hash string) {
+	if _, ok := s.Releases[tag]; !ok {
+		s.Releases[tag] = make(map[string]string)
+	}
+	if _, ok := s.Releases[tag][asset]; !ok {
+		s.Releases[tag][asset] = hash
+	}
+}
+
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create trust store directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write trust store: %w", err)
+	}
+
+	return os.Rename(tmpPath, s.path)
+}
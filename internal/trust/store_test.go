@@ -0,0 +1,51 @@
+package trust
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStore_CheckAndRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known_releases.json")
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	if err := s.Check("v1.0.0", "umono.tar.gz", "aaaa"); err != nil {
+		t.Errorf("Check() unexpected error for unseen asset: %v", err)
+	}
+
+	s.Record("v1.0.0", "umono.tar.gz", "aaaa")
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	s, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	if err := s.Check("v1.0.0", "umono.tar.gz", "aaaa"); err != nil {
+		t.Errorf("Check() unexpected error for pinned hash: %v", err)
+	}
+
+	err = s.Check("v1.0.0", "umono.tar.gz", "bbbb")
+	mismatch, ok := err.(*MismatchError)
+	if !ok {
+		t.Fatalf("Check() expected MismatchError, got %T", err)
+	}
+	if mismatch.Pinned != "aaaa" || mismatch.Received != "bbbb" {
+		t.Errorf("MismatchError = %+v", mismatch)
+	}
+
+	s.Record("v1.0.0", "umono.tar.gz", "bbbb")
+	if got := s.Releases["v1.0.0"]["umono.tar.gz"]; got != "aaaa" {
+		t.Errorf("Record() replaced existing pin with %s", got)
+	}
+
+	if err := s.Check("v1.1.0", "umono.tar.gz", "bbbb"); err != nil {
+		t.Errorf("Check() unexpected error for another tag: %v", err)
+	}
+}